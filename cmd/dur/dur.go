@@ -0,0 +1,239 @@
+/*
+Command dur is a calculator for ISO 8601 durations.
+
+Usage:
+
+	$ dur parse P1DT12H                              # validate a duration
+	P1DT12H
+	$ dur shift 2022-01-31T10:00:00Z P1M             # shift a time by a duration
+	2022-03-03T10:00:00Z
+	$ dur shift 2022-01-01 PT12H                     # precision grows as needed
+	2022-01-01T12Z
+	$ dur between 2022-01-01 2022-03-15T12:00Z       # duration between two times
+	P2M14DT12H
+	$ dur convert seconds PT1H30M                    # convert to seconds
+	5400
+	$ dur convert human P1DT2H                       # convert to text
+	1 day, 2 hours
+	$ printf 'PT45M\nPT30M\n' | dur sum              # sum durations from stdin
+	PT75M
+
+Times are given in ISO 8601 format, or as "now". Durations other than the
+one given to parse can also use Go syntax, such as 1h30m. Pass -json to get
+the result as a JSON object.
+
+The sum of durations with different signs, such as PT1M and -PT30S, is
+exact for hours, minutes and seconds. Years, months, weeks and days have no
+fixed length in hours, so sums that mix signs between them and the time,
+such as P1D and -PT1H, are rejected: ISO 8601 can't write them.
+*/
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"go.astrophena.name/exp/cmd"
+	"go.astrophena.name/exp/iso8601"
+)
+
+var (
+	jsonOutput = flag.Bool("json", false, "Print the result as JSON.")
+	ref        = flag.String("ref", "now", "Reference `time` for converting years, months and days to seconds.")
+)
+
+func main() {
+	cmd.SetDescription("ISO 8601 duration calculator. See https://go.astrophena.name/exp/cmd/dur for full documentation.")
+	cmd.SetArgsUsage("[flags] parse|shift|between|convert|sum [args...]")
+	log.SetPrefix("dur: ")
+	cmd.HandleStartup()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+	}
+	switch name, args := args[0], args[1:]; name {
+	case "parse":
+		wantArgs(args, 1)
+		d, err := iso8601.ParseDuration(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		output(d.String(), struct {
+			Duration iso8601.Duration `json:"duration"`
+		}{d})
+	case "shift":
+		wantArgs(args, 2)
+		t, prec := parseTime(args[0])
+		d := parseDuration(args[1])
+		shifted := iso8601.FormatTime(d.Shift(t), iso8601.TimeLayout{Precision: shiftPrecision(prec, d)})
+		output(shifted, struct {
+			Time string `json:"time"`
+		}{shifted})
+	case "between":
+		wantArgs(args, 2)
+		a, _ := parseTime(args[0])
+		b, _ := parseTime(args[1])
+		d := iso8601.Between(a, b)
+		output(d.String(), struct {
+			Duration iso8601.Duration `json:"duration"`
+		}{d})
+	case "convert":
+		wantArgs(args, 2)
+		d := parseDuration(args[1])
+		switch args[0] {
+		case "seconds":
+			refTime, _ := parseTime(*ref)
+			td, err := d.TimeDuration(refTime)
+			if err != nil {
+				log.Fatal(err)
+			}
+			output(strconv.FormatFloat(td.Seconds(), 'f', -1, 64), struct {
+				Seconds float64 `json:"seconds"`
+			}{td.Seconds()})
+		case "human":
+			text := d.Humanize(iso8601.HumanizeOptions{})
+			output(text, struct {
+				Text string `json:"text"`
+			}{text})
+		default:
+			log.Fatalf("Can't convert to %q: want seconds or human.", args[0])
+		}
+	case "sum":
+		wantArgs(args, 0)
+		var (
+			sum   iso8601.Duration
+			count int
+		)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if scanner.Text() == "" {
+				continue
+			}
+			var err error
+			if sum, err = addDurations(sum, parseDuration(scanner.Text())); err != nil {
+				log.Fatalf("Line %d: %v", count+1, err)
+			}
+			count++
+		}
+		if err := scanner.Err(); err != nil {
+			log.Fatal(err)
+		}
+		output(sum.String(), struct {
+			Duration iso8601.Duration `json:"duration"`
+			Count    int              `json:"count"`
+		}{sum, count})
+	default:
+		log.Printf("Unknown command %q.", name)
+		usage()
+	}
+}
+
+func usage() {
+	flag.Usage()
+	os.Exit(2)
+}
+
+func wantArgs(args []string, n int) {
+	if len(args) != n {
+		usage()
+	}
+}
+
+func parseTime(s string) (time.Time, iso8601.Unit) {
+	if s == "now" {
+		return time.Now(), iso8601.Seconds
+	}
+	t, prec, err := iso8601.ParseTime(s)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return t, prec
+}
+
+// shiftPrecision returns the precision to write a time of precision prec
+// shifted by d with: that of the time or of the lowest component of d,
+// whichever is finer.
+func shiftPrecision(prec iso8601.Unit, d iso8601.Duration) iso8601.Unit {
+	lowest := prec
+	for i, n := range []int{d.Y, d.M, d.W, d.D, d.TH, d.TM, d.TS} {
+		if u := iso8601.Years + iso8601.Unit(i); n != 0 && u > lowest {
+			lowest = u
+		}
+	}
+	if d.Frac != 0 {
+		lowest = iso8601.Seconds
+	}
+	// A week date stays one only when shifted by whole weeks.
+	if lowest == iso8601.Weeks && (prec != iso8601.Weeks || d.Y != 0 || d.M != 0) {
+		lowest = iso8601.Days
+	}
+	return lowest
+}
+
+// addDurations returns the sum of a and b. Unlike Duration.Add, it can sum
+// hours, minutes and seconds of different signs, since they have fixed
+// lengths: PT1M plus -PT30S is PT30S.
+func addDurations(a, b iso8601.Duration) (iso8601.Duration, error) {
+	sum, err := a.Add(b)
+	if err == nil || errors.Is(err, iso8601.ErrOverflow) {
+		return sum, err
+	}
+	aDate, aTime := splitTime(a)
+	bDate, bTime := splitTime(b)
+	date, dateErr := aDate.Add(bDate)
+	if dateErr != nil {
+		return iso8601.Duration{}, err
+	}
+	// A time part converts exactly, whatever the reference time.
+	at, aErr := aTime.TimeDuration(time.Time{})
+	bt, bErr := bTime.TimeDuration(time.Time{})
+	if aErr != nil || bErr != nil || (at > 0 && bt > math.MaxInt64-at) || (at < 0 && bt < math.MinInt64-at) {
+		return iso8601.Duration{}, iso8601.ErrOverflow
+	}
+	if sum, err = date.Add(iso8601.FromTimeDuration(at+bt, iso8601.Hours)); err != nil {
+		return iso8601.Duration{}, fmt.Errorf("can't add %s and %s: %w", a, b, err)
+	}
+	return sum, nil
+}
+
+// splitTime splits d into its years, months, weeks and days, and its hours,
+// minutes and seconds.
+func splitTime(d iso8601.Duration) (date, t iso8601.Duration) {
+	date = iso8601.Duration{Y: d.Y, M: d.M, W: d.W, D: d.D, Negative: d.Negative}
+	t = iso8601.Duration{TH: d.TH, TM: d.TM, TS: d.TS, Negative: d.Negative}
+	if d.Frac != 0 && d.FracUnit >= iso8601.Hours {
+		t.Frac, t.FracUnit = d.Frac, d.FracUnit
+	} else {
+		date.Frac, date.FracUnit = d.Frac, d.FracUnit
+	}
+	return date, t
+}
+
+func parseDuration(s string) iso8601.Duration {
+	var d iso8601.Duration
+	if err := d.Set(s); err != nil {
+		log.Fatal(err)
+	}
+	return d
+}
+
+// output prints text, or v as JSON if the -json flag is set.
+func output(text string, v any) {
+	if !*jsonOutput {
+		fmt.Println(text)
+		return
+	}
+	enc := json.NewEncoder(os.Stdout)
+	if err := enc.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -93,6 +93,7 @@ import (
 	"time"
 
 	"go.astrophena.name/exp/cmd"
+	"go.astrophena.name/exp/iso8601"
 
 	"github.com/godbus/dbus/v5"
 )
@@ -177,9 +178,17 @@ func main() {
 	cmd.SetArgsUsage("[commands...]")
 	log.SetPrefix("i3status-wrapper: ")
 
-	timeout := flag.Duration("timeout", 5*time.Second, "Timeout for custom command execution.")
+	timeoutFlag := iso8601.DurationFlag("timeout", iso8601.Duration{TS: 5}, "Timeout for custom command execution, such as PT5S or 5s.")
 	cmd.HandleStartup()
 
+	timeout, err := timeoutFlag.TimeDuration(time.Now())
+	if err != nil {
+		log.Fatalf("Invalid -timeout: %v", err)
+	}
+	if timeout < 0 {
+		log.Fatal("Invalid -timeout: can't be negative.")
+	}
+
 	bus, err := dbus.SessionBus()
 	if err != nil {
 		log.Fatal(err)
@@ -192,7 +201,7 @@ func main() {
 		cmdList[k] = &customCommand{
 			command: cmdSplit[0],
 			args:    cmdSplit[1:],
-			timeout: *timeout,
+			timeout: timeout,
 			result:  &i3bar{},
 			order:   k,
 		}
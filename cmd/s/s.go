@@ -13,15 +13,24 @@ import (
 	"time"
 
 	"go.astrophena.name/exp/cmd"
+	"go.astrophena.name/exp/iso8601"
 )
 
 func main() {
 	addr := flag.String("addr", "localhost:3000", "Listen on `host:port`.")
-	shutdownTimeout := flag.Duration("shutdown-timeout", 5*time.Second, "Graceful shutdown timeout.")
+	shutdownTimeout := iso8601.DurationFlag("shutdown-timeout", iso8601.Duration{TS: 5}, "Graceful shutdown timeout, such as PT5S or 5s.")
 	cmd.SetDescription("Simple HTTP server that serves files.")
 	cmd.SetArgsUsage("[dir]")
 	cmd.HandleStartup()
 
+	timeout, err := shutdownTimeout.TimeDuration(time.Now())
+	if err != nil {
+		log.Fatalf("Invalid -shutdown-timeout: %v", err)
+	}
+	if timeout < 0 {
+		log.Fatal("Invalid -shutdown-timeout: can't be negative.")
+	}
+
 	dir := "."
 	if len(os.Args) > 1 {
 		dir = os.Args[1]
@@ -55,7 +64,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 	srv.Shutdown(shutdownCtx)
 }
@@ -0,0 +1,357 @@
+package iso8601
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseTime parses an ISO 8601 date or date and time, such as 2022-02-03,
+// 2022-W05-4, 2022-034, 20220203T101500Z or 2022-02-03T10:15:00.5+03:00.
+//
+// Reduced precision is allowed: 2022, 2022-02 and 2022-W05 are parsed as the
+// start of the year, month and week, and the time of day can stop at hours or
+// minutes, the lowest of which can have a decimal fraction. ParseTime returns
+// the lowest order unit given in s as the precision: Years, Months, Weeks,
+// Days, Hours, Minutes or Seconds.
+//
+// Times without a time zone designator are interpreted in UTC.
+func ParseTime(s string) (time.Time, Unit, error) {
+	return ParseTimeInLocation(s, time.UTC)
+}
+
+// ParseTimeInLocation is like ParseTime, but interprets times without a time
+// zone designator in the given location.
+func ParseTimeInLocation(s string, loc *time.Location) (time.Time, Unit, error) {
+	t, prec, err := parseTime(s, loc)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("could not parse time %q: %w", s, err)
+	}
+	return t, prec, nil
+}
+
+func parseTime(s string, loc *time.Location) (time.Time, Unit, error) {
+	date, clock, hasClock := strings.Cut(s, "T")
+
+	if len(date) < 4 || !isDigits(date[:4]) {
+		return time.Time{}, 0, errors.New("expected four-digit year")
+	}
+	year, _ := strconv.Atoi(date[:4])
+	rest := date[4:]
+	extended := strings.HasPrefix(rest, "-")
+	if extended {
+		rest = rest[1:]
+		if rest == "" {
+			return time.Time{}, 0, errors.New("unexpected end of date")
+		}
+	}
+
+	var (
+		t    time.Time
+		prec Unit
+		err  error
+	)
+	switch {
+	case rest == "":
+		t, prec = time.Date(year, time.January, 1, 0, 0, 0, 0, loc), Years
+	case rest[0] == 'W':
+		t, prec, err = parseWeekDate(year, rest[1:], extended, loc)
+	case extended && len(rest) == 2:
+		t, prec, err = parseCalendarDate(year, rest, "", loc)
+	case extended && len(rest) == 5 && rest[2] == '-':
+		t, prec, err = parseCalendarDate(year, rest[:2], rest[3:], loc)
+	case !extended && len(rest) == 4:
+		t, prec, err = parseCalendarDate(year, rest[:2], rest[2:], loc)
+	case len(rest) == 3:
+		t, prec, err = parseOrdinalDate(year, rest, loc)
+	default:
+		err = errors.New("unknown date format")
+	}
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	if !hasClock {
+		return t, prec, nil
+	}
+	if prec != Days {
+		return time.Time{}, 0, errors.New("time of day requires a complete date")
+	}
+	return parseClock(t, clock, extended)
+}
+
+func parseCalendarDate(year int, month, day string, loc *time.Location) (time.Time, Unit, error) {
+	if !isDigits(month) || !isDigits(day) {
+		return time.Time{}, 0, errors.New("expected digits")
+	}
+	m, _ := strconv.Atoi(month)
+	if m < 1 || m > 12 {
+		return time.Time{}, 0, fmt.Errorf("month %d out of range", m)
+	}
+	if day == "" {
+		return time.Date(year, time.Month(m), 1, 0, 0, 0, 0, loc), Months, nil
+	}
+	d, _ := strconv.Atoi(day)
+	if d < 1 || d > daysIn(year, time.Month(m)) {
+		return time.Time{}, 0, fmt.Errorf("day %d out of range", d)
+	}
+	return time.Date(year, time.Month(m), d, 0, 0, 0, 0, loc), Days, nil
+}
+
+func parseOrdinalDate(year int, day string, loc *time.Location) (time.Time, Unit, error) {
+	if !isDigits(day) {
+		return time.Time{}, 0, errors.New("expected digits")
+	}
+	d, _ := strconv.Atoi(day)
+	last := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	if d < 1 || d > last {
+		return time.Time{}, 0, fmt.Errorf("ordinal day %d out of range", d)
+	}
+	return time.Date(year, time.January, d, 0, 0, 0, 0, loc), Days, nil
+}
+
+func parseWeekDate(year int, s string, extended bool, loc *time.Location) (time.Time, Unit, error) {
+	if len(s) < 2 || !isDigits(s[:2]) {
+		return time.Time{}, 0, errors.New("expected two-digit week")
+	}
+	week, _ := strconv.Atoi(s[:2])
+	s = s[2:]
+
+	weekday, prec := 1, Weeks
+	if s != "" {
+		if extended {
+			if s[0] != '-' {
+				return time.Time{}, 0, errors.New("expected '-' before day of week")
+			}
+			s = s[1:]
+		}
+		if len(s) != 1 || s[0] < '1' || s[0] > '7' {
+			return time.Time{}, 0, errors.New("expected day of week from 1 to 7")
+		}
+		weekday, prec = int(s[0]-'0'), Days
+	}
+
+	t := isoWeekStart(year, loc).AddDate(0, 0, (week-1)*7+weekday-1)
+	if y, w := t.ISOWeek(); week < 1 || y != year || w != week {
+		return time.Time{}, 0, fmt.Errorf("week %d out of range", week)
+	}
+	return t, prec, nil
+}
+
+// isoWeekStart returns the Monday of the first ISO week of the year, which is
+// the week with the year's first Thursday in it.
+func isoWeekStart(year int, loc *time.Location) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	return jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7)
+}
+
+// parseClock parses the time of day and time zone designator that follow the
+// date d.
+func parseClock(d time.Time, s string, extended bool) (time.Time, Unit, error) {
+	clock, zone := s, ""
+	if i := strings.IndexAny(s, "Z+-"); i >= 0 {
+		clock, zone = s[:i], s[i:]
+	}
+
+	loc := d.Location()
+	if zone != "" {
+		var err error
+		loc, err = parseZone(zone, extended)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+	}
+
+	clock, frac, hasFrac := cutFrac(clock)
+	var fields []string
+	if extended {
+		fields = strings.Split(clock, ":")
+	} else {
+		for len(clock) > 2 {
+			fields = append(fields, clock[:2])
+			clock = clock[2:]
+		}
+		fields = append(fields, clock)
+	}
+	if len(fields) > 3 {
+		return time.Time{}, 0, errors.New("too many time components")
+	}
+
+	var vals [3]int
+	for i, f := range fields {
+		if len(f) != 2 || !isDigits(f) {
+			return time.Time{}, 0, errors.New("expected two-digit time component")
+		}
+		vals[i], _ = strconv.Atoi(f)
+	}
+	hour, min, sec := vals[0], vals[1], vals[2]
+	if hour > 24 || min > 59 || sec > 59 {
+		return time.Time{}, 0, errors.New("time of day out of range")
+	}
+	prec := Hours + Unit(len(fields)-1)
+
+	var nsec time.Duration
+	if hasFrac {
+		if frac == "" || !isDigits(frac) {
+			return time.Time{}, 0, errors.New("expected digits after decimal sign")
+		}
+		f, err := parseFrac(frac)
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+		nsec = fracOf([...]time.Duration{time.Hour, time.Minute, time.Second}[len(fields)-1], f)
+	}
+	if hour == 24 && (min != 0 || sec != 0 || nsec != 0) {
+		return time.Time{}, 0, errors.New("time of day out of range")
+	}
+
+	t := time.Date(d.Year(), d.Month(), d.Day(), hour, min, sec, 0, loc).Add(nsec)
+	return t, prec, nil
+}
+
+func parseZone(s string, extended bool) (*time.Location, error) {
+	if s == "Z" {
+		return time.UTC, nil
+	}
+	sign := 1
+	if s[0] == '-' {
+		sign = -1
+	}
+	s = s[1:]
+	if extended && len(s) == 5 && s[2] == ':' {
+		s = s[:2] + s[3:]
+	}
+	if (len(s) != 2 && len(s) != 4) || !isDigits(s) {
+		return nil, errors.New("invalid time zone designator")
+	}
+	hours, _ := strconv.Atoi(s[:2])
+	var mins int
+	if len(s) == 4 {
+		mins, _ = strconv.Atoi(s[2:])
+	}
+	if hours > 23 || mins > 59 {
+		return nil, errors.New("time zone offset out of range")
+	}
+	return time.FixedZone("", sign*(hours*3600+mins*60)), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// daysIn returns the number of days in the month of the year.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// DateForm is a way of writing the date part of a time.
+type DateForm int
+
+const (
+	// CalendarDate is the year, month and day, such as 2022-02-03.
+	CalendarDate DateForm = iota
+	// OrdinalDate is the year and day of year, such as 2022-034.
+	OrdinalDate
+	// WeekDate is the ISO week-numbering year, week and day of week, such as
+	// 2022-W05-4.
+	WeekDate
+)
+
+// TimeLayout describes how FormatTime writes a time.
+type TimeLayout struct {
+	// Form is the way of writing the date.
+	Form DateForm
+	// Basic selects the basic format, such as 20220203T101500Z, instead of the
+	// extended one, such as 2022-02-03T10:15:00Z.
+	Basic bool
+	// Precision is the lowest order unit to write. Months implies
+	// CalendarDate and Weeks implies WeekDate. The zero value means Seconds.
+	//
+	// With Seconds precision the fraction of a second is written with as many
+	// digits as needed.
+	Precision Unit
+}
+
+// FormatTime returns an ISO 8601 representation of t. The time zone
+// designator is written whenever the time of day is.
+func FormatTime(t time.Time, l TimeLayout) string {
+	prec := l.Precision
+	if prec == 0 {
+		prec = Seconds
+	}
+	form := l.Form
+	switch prec {
+	case Months:
+		form = CalendarDate
+	case Weeks:
+		form = WeekDate
+	}
+	dash, colon := "-", ":"
+	if l.Basic {
+		dash, colon = "", ""
+	}
+
+	var b strings.Builder
+	switch form {
+	case WeekDate:
+		year, week := t.ISOWeek()
+		fmt.Fprintf(&b, "%04d", year)
+		if prec == Years {
+			break
+		}
+		fmt.Fprintf(&b, "%sW%02d", dash, week)
+		if prec == Weeks {
+			break
+		}
+		fmt.Fprintf(&b, "%s%d", dash, (int(t.Weekday())+6)%7+1)
+	case OrdinalDate:
+		fmt.Fprintf(&b, "%04d", t.Year())
+		if prec == Years {
+			break
+		}
+		fmt.Fprintf(&b, "%s%03d", dash, t.YearDay())
+	default:
+		fmt.Fprintf(&b, "%04d", t.Year())
+		if prec == Years {
+			break
+		}
+		fmt.Fprintf(&b, "%s%02d", dash, t.Month())
+		if prec == Months {
+			break
+		}
+		fmt.Fprintf(&b, "%s%02d", dash, t.Day())
+	}
+	if prec < Hours {
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "T%02d", t.Hour())
+	if prec >= Minutes {
+		fmt.Fprintf(&b, "%s%02d", colon, t.Minute())
+	}
+	if prec >= Seconds {
+		fmt.Fprintf(&b, "%s%02d", colon, t.Second())
+		if ns := t.Nanosecond(); ns != 0 {
+			b.WriteString("." + fracString(ns))
+		}
+	}
+
+	_, offset := t.Zone()
+	if offset == 0 {
+		b.WriteString("Z")
+		return b.String()
+	}
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	fmt.Fprintf(&b, "%s%02d%s%02d", sign, offset/3600, colon, offset%3600/60)
+	return b.String()
+}
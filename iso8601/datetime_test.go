@@ -0,0 +1,149 @@
+package iso8601
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanParseTime(t *testing.T) {
+	cases := []struct {
+		from     string
+		want     string
+		wantPrec Unit
+	}{
+		{"2022", "2022-01-01T00:00:00Z", Years},
+		{"2022-05", "2022-05-01T00:00:00Z", Months},
+		{"2022-02-03", "2022-02-03T00:00:00Z", Days},
+		{"20220203", "2022-02-03T00:00:00Z", Days},
+		{"2022-W05", "2022-01-31T00:00:00Z", Weeks},
+		{"2022W05", "2022-01-31T00:00:00Z", Weeks},
+		{"2022-W05-3", "2022-02-02T00:00:00Z", Days},
+		{"2022W053", "2022-02-02T00:00:00Z", Days},
+		{"2020-W53-7", "2021-01-03T00:00:00Z", Days},
+		{"2019-W01-1", "2018-12-31T00:00:00Z", Days},
+		{"2022-035", "2022-02-04T00:00:00Z", Days},
+		{"2022035", "2022-02-04T00:00:00Z", Days},
+		{"2020-366", "2020-12-31T00:00:00Z", Days},
+		{"20220203T101500Z", "2022-02-03T10:15:00Z", Seconds},
+		{"2022-02-03T10:15:00Z", "2022-02-03T10:15:00Z", Seconds},
+		{"2022-02-03T10:15Z", "2022-02-03T10:15:00Z", Minutes},
+		{"2022-02-03T10", "2022-02-03T10:00:00Z", Hours},
+		{"2022-02-03T10.5", "2022-02-03T10:30:00Z", Hours},
+		{"2022-02-03T10:15,5", "2022-02-03T10:15:30Z", Minutes},
+		{"2022-02-03T10:15:00.25+03:00", "2022-02-03T10:15:00.25+03:00", Seconds},
+		{"20220203T101500-0530", "2022-02-03T10:15:00-05:30", Seconds},
+		{"2022-02-03T10:15:00-05", "2022-02-03T10:15:00-05:00", Seconds},
+		{"2022-W05-3T10:15:00Z", "2022-02-02T10:15:00Z", Seconds},
+		{"2022-035T10:15Z", "2022-02-04T10:15:00Z", Minutes},
+		{"2022-02-03T24:00:00Z", "2022-02-04T00:00:00Z", Seconds},
+	}
+
+	for _, c := range cases {
+		got, prec, err := ParseTime(c.from)
+		if err != nil {
+			t.Fatalf("%s: %v", c.from, err)
+		}
+		if want := mustParseRFC3339(t, c.want); !want.Equal(got) {
+			t.Fatalf("%s: want=%s, got=%s", c.from, want, got)
+		}
+		if prec != c.wantPrec {
+			t.Fatalf("%s: want precision %d, got %d", c.from, c.wantPrec, prec)
+		}
+	}
+}
+
+func TestCanParseTimeInLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := ParseTimeInLocation("2022-07-01T12:00", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustParseRFC3339(t, "2022-07-01T12:00:00-04:00"); !want.Equal(got) {
+		t.Fatalf("want=%s, got=%s", want, got)
+	}
+	got, _, err = ParseTimeInLocation("2022-07-01T12:00Z", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := mustParseRFC3339(t, "2022-07-01T12:00:00Z"); !want.Equal(got) {
+		t.Fatalf("want=%s, got=%s", want, got)
+	}
+}
+
+func TestCanRejectBadTime(t *testing.T) {
+	cases := []string{
+		"",
+		"22",
+		"2022-",
+		"202205",
+		"2022-13",
+		"2022-02-30",
+		"2021-366",
+		"2022-000",
+		"2021-W53",
+		"2022-W00",
+		"2022-W05-8",
+		"2022-W053",
+		"2022W05-3",
+		"2022-05T10:00",
+		"2022-02-03T",
+		"2022-02-03T101500",
+		"20220203T10:15:00",
+		"2022-02-03T10:15:00:00",
+		"2022-02-03T25:00",
+		"2022-02-03T10:60",
+		"2022-02-03T24:00:01",
+		"2022-02-03T10:15:00+24:00",
+		"2022-02-03T10:15:00+3",
+		"2022-02-03T10:15:00Zulu",
+		"2022-02-03T10:15.",
+		"2022-02-03Z",
+	}
+	for _, c := range cases {
+		if got, _, err := ParseTime(c); err == nil {
+			t.Fatalf("%q: expected error, got %s", c, got)
+		}
+	}
+}
+
+func TestCanFormatTime(t *testing.T) {
+	tm := mustParseRFC3339(t, "2022-02-03T10:15:05.5+03:00")
+	cases := []struct {
+		layout TimeLayout
+		want   string
+	}{
+		{TimeLayout{}, "2022-02-03T10:15:05.5+03:00"},
+		{TimeLayout{Basic: true}, "20220203T101505.5+0300"},
+		{TimeLayout{Precision: Years}, "2022"},
+		{TimeLayout{Precision: Months}, "2022-02"},
+		{TimeLayout{Precision: Weeks}, "2022-W05"},
+		{TimeLayout{Precision: Weeks, Basic: true}, "2022W05"},
+		{TimeLayout{Precision: Days}, "2022-02-03"},
+		{TimeLayout{Precision: Days, Form: WeekDate}, "2022-W05-4"},
+		{TimeLayout{Precision: Days, Form: OrdinalDate}, "2022-034"},
+		{TimeLayout{Precision: Days, Form: OrdinalDate, Basic: true}, "2022034"},
+		{TimeLayout{Precision: Hours}, "2022-02-03T10+03:00"},
+		{TimeLayout{Precision: Minutes, Form: WeekDate}, "2022-W05-4T10:15+03:00"},
+		{TimeLayout{Precision: Seconds, Basic: true}, "20220203T101505.5+0300"},
+	}
+	for _, c := range cases {
+		got := FormatTime(tm, c.layout)
+		if got != c.want {
+			t.Fatalf("%+v: want=%s, got=%s", c.layout, c.want, got)
+		}
+		back, _, err := ParseTime(got)
+		if err != nil {
+			t.Fatalf("%s: %v", got, err)
+		}
+		if again := FormatTime(back, c.layout); again != got {
+			t.Fatalf("round trip: want=%s, got=%s", got, again)
+		}
+	}
+
+	if got := FormatTime(mustParseRFC3339(t, "2021-01-03T00:00:00Z"), TimeLayout{Form: WeekDate}); got != "2020-W53-7T00:00:00Z" {
+		t.Fatalf("want=2020-W53-7T00:00:00Z, got=%s", got)
+	}
+}
@@ -0,0 +1,51 @@
+package iso8601
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// MarshalText satisfies encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText satisfies encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(b []byte) error {
+	tmp, err := ParseDuration(string(b))
+	if err != nil {
+		return err
+	}
+	*d = tmp
+	return nil
+}
+
+// MarshalBinary satisfies encoding.BinaryMarshaler. The binary form is the
+// same as the text one.
+func (d Duration) MarshalBinary() ([]byte, error) {
+	return d.MarshalText()
+}
+
+// UnmarshalBinary satisfies encoding.BinaryUnmarshaler.
+func (d *Duration) UnmarshalBinary(b []byte) error {
+	return d.UnmarshalText(b)
+}
+
+// Value satisfies driver.Valuer. Durations are stored as text.
+func (d Duration) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan satisfies sql.Scanner. It accepts text values.
+func (d *Duration) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return d.UnmarshalText([]byte(src))
+	case []byte:
+		return d.UnmarshalText(src)
+	case nil:
+		return errors.New("cannot scan NULL into Duration")
+	}
+	return fmt.Errorf("cannot scan %T into Duration", src)
+}
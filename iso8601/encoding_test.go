@@ -0,0 +1,105 @@
+package iso8601
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+var (
+	_ encoding.TextMarshaler     = Duration{}
+	_ encoding.TextUnmarshaler   = (*Duration)(nil)
+	_ encoding.BinaryMarshaler   = Duration{}
+	_ encoding.BinaryUnmarshaler = (*Duration)(nil)
+	_ driver.Valuer              = Duration{}
+	_ sql.Scanner                = (*Duration)(nil)
+)
+
+var encodingSample = Duration{Y: 1, M: 2, W: 3, D: 4, TH: 5, TM: 6, TS: 7, Frac: 500000000, FracUnit: Seconds, Negative: true}
+
+func TestCanRoundTripXML(t *testing.T) {
+	type doc struct {
+		XMLName xml.Name `xml:"doc"`
+		Elem    Duration `xml:"elem"`
+		Attr    Duration `xml:"attr,attr"`
+	}
+	want := doc{Elem: encodingSample, Attr: Duration{D: 1}}
+	b, err := xml.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := `<doc attr="P1D"><elem>-P1Y2M3W4DT5H6M7.5S</elem></doc>`; string(b) != s {
+		t.Fatalf("want=%s, got=%s", s, b)
+	}
+	var got doc
+	if err := xml.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Elem != want.Elem || got.Attr != want.Attr {
+		t.Fatalf("want=%+v, got=%+v", want, got)
+	}
+}
+
+func TestCanRoundTripGob(t *testing.T) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(encodingSample); err != nil {
+		t.Fatal(err)
+	}
+	var got Duration
+	if err := gob.NewDecoder(&buf).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got != encodingSample {
+		t.Fatalf("want=%+v, got=%+v", encodingSample, got)
+	}
+}
+
+func TestCanUseAsMapKey(t *testing.T) {
+	want := map[Duration]int{{D: 1}: 1, {TH: 2}: 2}
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := `{"P1D":1,"PT2H":2}`; string(b) != s {
+		t.Fatalf("want=%s, got=%s", s, b)
+	}
+	var got map[Duration]int
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) || got[Duration{D: 1}] != 1 || got[Duration{TH: 2}] != 2 {
+		t.Fatalf("want=%v, got=%v", want, got)
+	}
+}
+
+func TestSQL(t *testing.T) {
+	v, err := encodingSample.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !driver.IsValue(v) {
+		t.Fatalf("%#v is not a valid driver.Value", v)
+	}
+
+	for _, src := range []any{v, []byte(v.(string))} {
+		var got Duration
+		if err := got.Scan(src); err != nil {
+			t.Fatal(err)
+		}
+		if got != encodingSample {
+			t.Fatalf("want=%+v, got=%+v", encodingSample, got)
+		}
+	}
+
+	for _, src := range []any{nil, 42, "PZY"} {
+		var got Duration
+		if err := got.Scan(src); err == nil {
+			t.Fatalf("%#v: expected error, got none", src)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package iso8601
+
+import (
+	"flag"
+	"time"
+)
+
+// Set satisfies flag.Value, so that a *Duration can be used as a command
+// line flag. It parses s with ParseDurationLenient and, failing that, with
+// time.ParseDuration, so that flags that used to be time.Duration keep
+// accepting values like 5s or 1h30m.
+func (d *Duration) Set(s string) error {
+	tmp, err := ParseDurationLenient(s)
+	if err != nil {
+		td, tdErr := time.ParseDuration(s)
+		if tdErr != nil {
+			return err
+		}
+		tmp = FromTimeDuration(td, Hours)
+	}
+	*d = tmp
+	return nil
+}
+
+// DurationVar defines a Duration flag with the given name, default value and
+// usage string. The argument p points to a Duration variable in which to
+// store the value of the flag.
+func DurationVar(p *Duration, name string, value Duration, usage string) {
+	*p = value
+	flag.Var(p, name, usage)
+}
+
+// DurationFlag defines a Duration flag with the given name, default value
+// and usage string. The return value is the address of a Duration variable
+// that stores the value of the flag.
+func DurationFlag(name string, value Duration, usage string) *Duration {
+	p := new(Duration)
+	DurationVar(p, name, value, usage)
+	return p
+}
@@ -0,0 +1,54 @@
+package iso8601
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestDurationFlag(t *testing.T) {
+	cases := []struct {
+		arg  string
+		want Duration
+	}{
+		{"P1D", Duration{D: 1}},
+		{"pt1h30m", Duration{TH: 1, TM: 30}},
+		{"1h30m", Duration{TH: 1, TM: 30}},
+		{"-1.5s", Duration{TS: 1, Frac: 500000000, FracUnit: Seconds, Negative: true}},
+	}
+	for _, c := range cases {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var d Duration
+		fs.Var(&d, "timeout", "")
+		if err := fs.Parse([]string{"-timeout", c.arg}); err != nil {
+			t.Fatalf("%s: %v", c.arg, err)
+		}
+		if d != c.want {
+			t.Fatalf("%s: want=%+v, got=%+v", c.arg, c.want, d)
+		}
+	}
+
+	var d Duration
+	if err := d.Set("1 day"); err == nil {
+		t.Fatal("want error, got none")
+	}
+}
+
+func TestDurationVar(t *testing.T) {
+	// DurationFlag defines the flag on flag.CommandLine, so use a fresh one
+	// that can be defined on again when the test runs several times.
+	saved := flag.CommandLine
+	flag.CommandLine = flag.NewFlagSet("test", flag.ContinueOnError)
+	t.Cleanup(func() { flag.CommandLine = saved })
+
+	p := DurationFlag("test-duration", Duration{TS: 5}, "")
+	f := flag.Lookup("test-duration")
+	if f.DefValue != "PT5S" {
+		t.Fatalf("want=PT5S, got=%s", f.DefValue)
+	}
+	if err := f.Value.Set("P1W"); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Duration{W: 1}); *p != want {
+		t.Fatalf("want=%+v, got=%+v", want, *p)
+	}
+}
@@ -0,0 +1,211 @@
+package iso8601
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HumanStyle is a style of writing durations for people.
+type HumanStyle int
+
+const (
+	// LongStyle writes durations like "1 day, 2 hours".
+	LongStyle HumanStyle = iota
+	// ShortStyle writes durations like "1d 2h".
+	ShortStyle
+	// CompactStyle writes durations like "1d2h".
+	CompactStyle
+)
+
+// HumanizeOptions control how Humanize writes a duration.
+type HumanizeOptions struct {
+	Style HumanStyle
+	// MaxUnits limits how many non-zero components are written, starting
+	// from the highest order one. The rest are dropped, not rounded. Zero
+	// means no limit.
+	MaxUnits int
+}
+
+// humanUnits holds the names of units in the long style, singular and
+// plural, and in the short one.
+var humanUnits = [Seconds]struct {
+	singular, plural, short string
+}{
+	{"year", "years", "y"},
+	{"month", "months", "mo"},
+	{"week", "weeks", "w"},
+	{"day", "days", "d"},
+	{"hour", "hours", "h"},
+	{"minute", "minutes", "m"},
+	{"second", "seconds", "s"},
+}
+
+// Humanize returns a human-readable representation of d, such as
+// "1 day, 2 hours" or "1d 2h", depending on the style.
+func (d Duration) Humanize(opts HumanizeOptions) string {
+	sep := ", "
+	switch opts.Style {
+	case ShortStyle:
+		sep = " "
+	case CompactStyle:
+		sep = ""
+	}
+
+	var parts []string
+	for i, n := range [Seconds]int{d.Y, d.M, d.W, d.D, d.TH, d.TM, d.TS} {
+		if opts.MaxUnits > 0 && len(parts) == opts.MaxUnits {
+			break
+		}
+		hasFrac := d.Frac != 0 && d.FracUnit == Unit(i+1)
+		if n == 0 && !hasFrac {
+			continue
+		}
+		num := strconv.Itoa(n)
+		if hasFrac {
+			num += "." + fracString(d.Frac)
+		}
+		parts = append(parts, humanComponent(num, Unit(i+1), n == 1 && !hasFrac, opts.Style))
+	}
+	if len(parts) == 0 {
+		return humanComponent("0", Seconds, false, opts.Style)
+	}
+
+	s := strings.Join(parts, sep)
+	if d.Negative {
+		s = "-" + s
+	}
+	return s
+}
+
+func humanComponent(num string, u Unit, singular bool, style HumanStyle) string {
+	names := humanUnits[u-1]
+	if style != LongStyle {
+		return num + names.short
+	}
+	if singular {
+		return num + " " + names.singular
+	}
+	return num + " " + names.plural
+}
+
+// humanNames maps the unit names ParseHuman understands to units.
+var humanNames = map[string]Unit{
+	"y": Years, "yr": Years, "yrs": Years, "year": Years, "years": Years,
+	"mo": Months, "mos": Months, "month": Months, "months": Months,
+	"w": Weeks, "wk": Weeks, "wks": Weeks, "week": Weeks, "weeks": Weeks,
+	"d": Days, "day": Days, "days": Days,
+	"h": Hours, "hr": Hours, "hrs": Hours, "hour": Hours, "hours": Hours,
+	"m": Minutes, "min": Minutes, "mins": Minutes, "minute": Minutes, "minutes": Minutes,
+	"s": Seconds, "sec": Seconds, "secs": Seconds, "second": Seconds, "seconds": Seconds,
+}
+
+// ParseHuman leniently parses a human-readable duration, such as "1h30m",
+// "2 weeks 3 days" or "1 day, 2 hours and 5.5 minutes". It understands what
+// Humanize writes in any style.
+//
+// Units can be given in any order and case, and the same unit can appear more
+// than once; the components are added up with Duration.Add. A leading minus
+// sign makes the duration negative.
+func ParseHuman(s string) (Duration, error) {
+	var d Duration
+	rest := strings.ToLower(strings.TrimSpace(s))
+	neg := strings.HasPrefix(rest, "-")
+	if neg {
+		rest = rest[1:]
+	}
+
+	var seen bool
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		if strings.HasPrefix(rest, "and ") {
+			rest = rest[len("and "):]
+			continue
+		}
+		if rest == "" {
+			break
+		}
+
+		i := 0
+		for i < len(rest) && isDigit(rest[i]) {
+			i++
+		}
+		// A comma is a decimal sign only if digits follow it right away.
+		if i > 0 && i+1 < len(rest) && (rest[i] == '.' || rest[i] == ',') && isDigit(rest[i+1]) {
+			i++
+			for i < len(rest) && isDigit(rest[i]) {
+				i++
+			}
+		}
+		if i == 0 {
+			return Duration{}, fmt.Errorf("could not parse %q: expected a number", s)
+		}
+		num := rest[:i]
+		rest = strings.TrimLeft(rest[i:], " \t")
+
+		j := 0
+		for j < len(rest) && rest[j] >= 'a' && rest[j] <= 'z' {
+			j++
+		}
+		u, ok := humanNames[rest[:j]]
+		if !ok {
+			return Duration{}, fmt.Errorf("could not parse %q: unknown unit %q", s, rest[:j])
+		}
+		rest = rest[j:]
+
+		c, err := humanDuration(num, u)
+		if err != nil {
+			return Duration{}, fmt.Errorf("could not parse %q: %w", s, err)
+		}
+		if d, err = d.Add(c); err != nil {
+			return Duration{}, fmt.Errorf("could not parse %q: %w", s, err)
+		}
+		seen = true
+	}
+	if !seen {
+		return Duration{}, errors.New("could not parse empty duration")
+	}
+
+	if neg {
+		d = d.Neg()
+	}
+	return d, nil
+}
+
+// humanDuration returns a Duration with a single component.
+func humanDuration(num string, u Unit) (Duration, error) {
+	var d Duration
+	intPart, fracPart, hasFrac := cutFrac(num)
+	n, err := strconv.Atoi(intPart)
+	if err != nil {
+		return d, ErrOverflow
+	}
+	if hasFrac {
+		if d.Frac, err = parseFrac(fracPart); err != nil {
+			return d, err
+		}
+		if d.Frac != 0 {
+			d.FracUnit = u
+		}
+	}
+	switch u {
+	case Years:
+		d.Y = n
+	case Months:
+		d.M = n
+	case Weeks:
+		d.W = n
+	case Days:
+		d.D = n
+	case Hours:
+		d.TH = n
+	case Minutes:
+		d.TM = n
+	case Seconds:
+		d.TS = n
+	}
+	return d, nil
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
@@ -0,0 +1,105 @@
+package iso8601
+
+import "testing"
+
+func TestHumanize(t *testing.T) {
+	cases := []struct {
+		d    string
+		opts HumanizeOptions
+		want string
+	}{
+		{"P1DT2H", HumanizeOptions{}, "1 day, 2 hours"},
+		{"P1DT2H", HumanizeOptions{Style: ShortStyle}, "1d 2h"},
+		{"P1DT2H", HumanizeOptions{Style: CompactStyle}, "1d2h"},
+		{"P2Y1M3W", HumanizeOptions{}, "2 years, 1 month, 3 weeks"},
+		{"P2Y1M3W", HumanizeOptions{Style: ShortStyle}, "2y 1mo 3w"},
+		{"PT1M1S", HumanizeOptions{}, "1 minute, 1 second"},
+		{"PT1.5H", HumanizeOptions{}, "1.5 hours"},
+		{"PT0.5S", HumanizeOptions{Style: CompactStyle}, "0.5s"},
+		{"P0D", HumanizeOptions{}, "0 seconds"},
+		{"P0D", HumanizeOptions{Style: ShortStyle}, "0s"},
+		{"-PT30M", HumanizeOptions{}, "-30 minutes"},
+		{"P1Y2M3DT4H5M6S", HumanizeOptions{MaxUnits: 2}, "1 year, 2 months"},
+		{"P1Y3DT4H", HumanizeOptions{MaxUnits: 2, Style: ShortStyle}, "1y 3d"},
+	}
+	for _, c := range cases {
+		d, err := ParseDuration(c.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := d.Humanize(c.opts); got != c.want {
+			t.Fatalf("%s with %+v: want=%q, got=%q", c.d, c.opts, c.want, got)
+		}
+	}
+}
+
+func TestParseHuman(t *testing.T) {
+	cases := []struct {
+		from string
+		want string
+	}{
+		{"1h30m", "PT1H30M"},
+		{"2 weeks 3 days", "P2W3D"},
+		{"1 day, 2 hours", "P1DT2H"},
+		{"1 day, 2 hours and 5.5 minutes", "P1DT2H5.5M"},
+		{"1d 2h", "P1DT2H"},
+		{"1d2h", "P1DT2H"},
+		{"1mo2m", "P1MT2M"},
+		{"  3 Years  ", "P3Y"},
+		{"1,5 hours", "PT1.5H"},
+		{"1.5 days 2 hours", "P1DT14H"},
+		{"30m 1h", "PT1H30M"},
+		{"1 hr 1 hr", "PT2H"},
+		{"-1 min", "-PT1M"},
+		{"0s", "P0D"},
+		{"1 sec", "PT1S"},
+	}
+	for _, c := range cases {
+		got, err := ParseHuman(c.from)
+		if err != nil {
+			t.Fatalf("%q: %v", c.from, err)
+		}
+		if got.String() != c.want {
+			t.Fatalf("%q: want=%s, got=%s", c.from, c.want, got)
+		}
+	}
+}
+
+func TestParseHumanRoundTrip(t *testing.T) {
+	for _, s := range []string{"P1Y2M3W4DT5H6M7S", "-PT1.25S", "P1D", "P0D"} {
+		d, err := ParseDuration(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, style := range []HumanStyle{LongStyle, ShortStyle, CompactStyle} {
+			h := d.Humanize(HumanizeOptions{Style: style})
+			got, err := ParseHuman(h)
+			if err != nil {
+				t.Fatalf("%q: %v", h, err)
+			}
+			if got != d {
+				t.Fatalf("%q: want=%s, got=%s", h, d, got)
+			}
+		}
+	}
+}
+
+func TestCanRejectBadHuman(t *testing.T) {
+	cases := []string{
+		"",
+		"-",
+		"and",
+		"hours",
+		"1",
+		"1 fortnight",
+		"1.5.5h",
+		"1 h and",
+		"1.5 months 2 days",
+		"99999999999999999999 days",
+	}
+	for _, c := range cases {
+		if got, err := ParseHuman(c); err == nil {
+			t.Fatalf("%q: expected error, got %s", c, got)
+		}
+	}
+}
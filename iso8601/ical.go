@@ -0,0 +1,513 @@
+package iso8601
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseICalDuration parses an iCalendar DURATION value, as defined in RFC
+// 5545, section 3.3.6, such as P15DT5H0M20S or -P2W. iCalendar durations
+// are a subset of ISO 8601 ones: they have no years, months or fractions,
+// and weeks can't be combined with other components.
+//
+// Duration.Shift adds such durations the way RFC 5545 requires: days and
+// weeks by the wall clock, and hours, minutes and seconds by elapsed time.
+func ParseICalDuration(s string) (Duration, error) {
+	d, err := ParseDuration(s)
+	if err != nil {
+		return Duration{}, err
+	}
+	p := strings.IndexByte(s, 'P')
+	if isAlternative(s[p+1:]) {
+		return Duration{}, &ParseError{Input: s, Offset: p + 1, Reason: "alternative format not allowed in iCalendar"}
+	}
+	var inTime bool
+	for i := p + 1; i < len(s); i++ {
+		var ok bool
+		switch c := s[i]; {
+		case isDigit(c) || c == 'D':
+			ok = true
+		case c == 'W':
+			ok = !strings.ContainsAny(s, "DT")
+		case c == 'T':
+			inTime, ok = true, true
+		default:
+			ok = inTime && designatorUnit(c, true) != 0
+		}
+		if !ok {
+			return Duration{}, &ParseError{Input: s, Offset: i, Reason: fmt.Sprintf("%q not allowed in iCalendar", s[i])}
+		}
+	}
+	return d, nil
+}
+
+// FormatICalDuration returns an iCalendar DURATION value for d. Weeks are
+// folded into days if d has other components. It returns an error if d has
+// years, months or a fraction.
+func FormatICalDuration(d Duration) (string, error) {
+	if d.Y != 0 || d.M != 0 {
+		return "", errors.New("iCalendar durations can't have years or months")
+	}
+	if d.Frac != 0 {
+		return "", errors.New("iCalendar durations can't have fractions")
+	}
+	if d.W != 0 && (d.D != 0 || d.HasTimePart()) {
+		d.D += d.W * 7
+		d.W = 0
+	}
+	return d.String(), nil
+}
+
+// RRule is an iCalendar recurrence rule, as defined in RFC 5545, section
+// 3.3.10, such as FREQ=MONTHLY;BYDAY=-1FR;COUNT=6.
+//
+// Only the FREQ, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY, BYMONTH and WKST
+// rule parts are supported.
+type RRule struct {
+	// Freq is the unit of the recurrence: Years for FREQ=YEARLY, Months for
+	// FREQ=MONTHLY and so on down to Seconds for FREQ=SECONDLY.
+	Freq Unit
+	// Interval is the number of Freq units between the periods the rule
+	// repeats in. Zero means 1.
+	Interval int
+	// Count is the number of occurrences. Zero means no limit.
+	Count int
+	// Until is the last instant an occurrence can be at. The zero time means
+	// no limit.
+	Until time.Time
+
+	ByDay      []WeekdayNum
+	ByMonthDay []int // negative values count from the end of month
+	ByMonth    []time.Month
+
+	// WeekStart is the day weeks start on, which decides the weeks a weekly
+	// rule with an Interval above 1 repeats in. Nil means Monday, the
+	// iCalendar default.
+	WeekStart *time.Weekday
+
+	untilPrec Unit // as given when parsed, zero otherwise
+}
+
+// WeekdayNum is a day of the week in the BYDAY rule part, such as FR for
+// every Friday or -1FR for the last Friday of the month or year.
+type WeekdayNum struct {
+	// N is the ordinal of the day within the month or year, negative when
+	// counting from the end. Zero means every such day.
+	N       int
+	Weekday time.Weekday
+}
+
+// weekdayCodes holds the iCalendar names of days of the week.
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+func (w WeekdayNum) String() string {
+	if w.N == 0 {
+		return weekdayCodes[w.Weekday]
+	}
+	return strconv.Itoa(w.N) + weekdayCodes[w.Weekday]
+}
+
+// freqNames holds the values of the FREQ rule part.
+var freqNames = [Seconds]string{"YEARLY", "MONTHLY", "WEEKLY", "DAILY", "HOURLY", "MINUTELY", "SECONDLY"}
+
+// ParseRRule parses an iCalendar recurrence rule. The RRULE: property name
+// in front of it is optional.
+//
+// An UNTIL without a time zone is interpreted in UTC.
+func ParseRRule(s string) (RRule, error) {
+	var r RRule
+	s = strings.TrimPrefix(s, "RRULE:")
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return RRule{}, fmt.Errorf("invalid rule part %q", part)
+		}
+		if seen[name] {
+			return RRule{}, fmt.Errorf("rule part %s given more than once", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			for i, f := range freqNames {
+				if f == value {
+					r.Freq = Unit(i + 1)
+				}
+			}
+			if r.Freq == 0 {
+				err = errors.New("unknown frequency")
+			}
+		case "INTERVAL":
+			r.Interval, err = parseRuleInt(value, 1, 0)
+		case "COUNT":
+			r.Count, err = parseRuleInt(value, 1, 0)
+		case "UNTIL":
+			var t time.Time
+			t, r.untilPrec, err = ParseTime(value)
+			if err == nil && r.untilPrec != Days && r.untilPrec != Seconds {
+				err = errors.New("want a date or a date and time")
+			}
+			r.Until = t
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				var w WeekdayNum
+				if w, err = parseWeekdayNum(v); err != nil {
+					break
+				}
+				r.ByDay = append(r.ByDay, w)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				var n int
+				if n, err = parseRuleInt(v, -31, 31); err != nil || n == 0 {
+					err = errors.New("want a day of month from 1 to 31 or -31 to -1")
+					break
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, v := range strings.Split(value, ",") {
+				var n int
+				if n, err = parseRuleInt(v, 1, 12); err != nil {
+					break
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			var w WeekdayNum
+			if w, err = parseWeekdayNum(value); err == nil && w.N != 0 {
+				err = errors.New("want a day of week without an ordinal")
+			}
+			r.WeekStart = &w.Weekday
+		default:
+			err = errors.New("unsupported rule part")
+		}
+		if err != nil {
+			return RRule{}, fmt.Errorf("invalid %s in recurrence rule: %w", name, err)
+		}
+	}
+
+	if r.Freq == 0 {
+		return RRule{}, errors.New("recurrence rule has no FREQ")
+	}
+	if r.Count != 0 && !r.Until.IsZero() {
+		return RRule{}, errors.New("recurrence rule can't have both COUNT and UNTIL")
+	}
+	if r.Freq != Years && r.Freq != Months {
+		for _, w := range r.ByDay {
+			if w.N != 0 {
+				return RRule{}, errors.New("BYDAY can have ordinals only with FREQ=MONTHLY or FREQ=YEARLY")
+			}
+		}
+	}
+	return r, nil
+}
+
+// parseRuleInt parses a signed integer between min and max. Zero max means
+// no upper limit.
+func parseRuleInt(s string, min, max int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New("want an integer")
+	}
+	if n < min || (max != 0 && n > max) {
+		return 0, fmt.Errorf("%d out of range", n)
+	}
+	return n, nil
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	var w WeekdayNum
+	if len(s) < 2 {
+		return w, fmt.Errorf("invalid day of week %q", s)
+	}
+	if num := s[:len(s)-2]; num != "" {
+		n, err := parseRuleInt(strings.TrimPrefix(num, "+"), -53, 53)
+		if err != nil || n == 0 {
+			return w, fmt.Errorf("invalid ordinal %q", num)
+		}
+		w.N = n
+	}
+	code := s[len(s)-2:]
+	for i, c := range weekdayCodes {
+		if c == code {
+			w.Weekday = time.Weekday(i)
+			return w, nil
+		}
+	}
+	return w, fmt.Errorf("invalid day of week %q", code)
+}
+
+// String returns the iCalendar representation of the rule, without the
+// RRULE: property name.
+func (r RRule) String() string {
+	var b strings.Builder
+	if r.Freq >= Years && r.Freq <= Seconds {
+		b.WriteString("FREQ=" + freqNames[r.Freq-1])
+	}
+	if r.Interval > 1 {
+		b.WriteString(";INTERVAL=" + strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		b.WriteString(";COUNT=" + strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		until := r.Until
+		prec := r.untilPrec
+		if prec != Days {
+			until, prec = until.UTC(), Seconds
+		}
+		b.WriteString(";UNTIL=" + FormatTime(until, TimeLayout{Basic: true, Precision: prec}))
+	}
+	for i, w := range r.ByDay {
+		if i == 0 {
+			b.WriteString(";BYDAY=")
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(w.String())
+	}
+	for i, n := range r.ByMonthDay {
+		if i == 0 {
+			b.WriteString(";BYMONTHDAY=")
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(n))
+	}
+	for i, m := range r.ByMonth {
+		if i == 0 {
+			b.WriteString(";BYMONTH=")
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.Itoa(int(m)))
+	}
+	if r.WeekStart != nil {
+		b.WriteString(";WKST=" + weekdayCodes[*r.WeekStart])
+	}
+	return b.String()
+}
+
+// Iter returns an iterator over the occurrences of the rule for an event
+// that starts at dtstart, in its location.
+//
+// Occurrences are found the way python-dateutil does: the rule is split into
+// periods of Interval Freq units, every candidate in a period is checked
+// against the BYxxx rule parts, and those that match are occurrences. The
+// parts that aren't given are taken from dtstart: for example, FREQ=MONTHLY
+// repeats on the day of month of dtstart. Consequently, dtstart itself is an
+// occurrence only if it matches the rule, and dates that don't exist, such
+// as February 30, are skipped.
+func (r RRule) Iter(dtstart time.Time) *RRuleIterator {
+	if r.Interval < 1 {
+		r.Interval = 1
+	}
+	if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+		switch r.Freq {
+		case Years:
+			if len(r.ByMonth) == 0 {
+				r.ByMonth = []time.Month{dtstart.Month()}
+			}
+			r.ByMonthDay = []int{dtstart.Day()}
+		case Months:
+			r.ByMonthDay = []int{dtstart.Day()}
+		case Weeks:
+			r.ByDay = []WeekdayNum{{Weekday: dtstart.Weekday()}}
+		}
+	}
+	return &RRuleIterator{r: r, dtstart: dtstart, next: dtstart}
+}
+
+// RRuleIterator iterates over the occurrences of a recurrence rule.
+type RRuleIterator struct {
+	r       RRule
+	dtstart time.Time
+	period  int         // index of the next period to expand
+	pending []time.Time // occurrences in the current period yet to return
+	next    time.Time   // start of the next period of a sub-daily rule
+	n       int         // number of occurrences returned
+	done    bool
+}
+
+// Next returns the next occurrence. It returns false when all occurrences
+// have been produced.
+func (it *RRuleIterator) Next() (time.Time, bool) {
+	for len(it.pending) == 0 {
+		if it.done {
+			return time.Time{}, false
+		}
+		it.expand()
+	}
+	t := it.pending[0]
+	it.pending = it.pending[1:]
+	if (it.r.Count > 0 && it.n == it.r.Count) || (!it.r.Until.IsZero() && t.After(it.r.Until)) {
+		it.done, it.pending = true, nil
+		return time.Time{}, false
+	}
+	it.n++
+	return t, true
+}
+
+// expand adds the occurrences in the next period to the pending ones.
+func (it *RRuleIterator) expand() {
+	r, dtstart := it.r, it.dtstart
+	if r.Freq >= Hours {
+		it.expandTime()
+		return
+	}
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	ns, loc := dtstart.Nanosecond(), dtstart.Location()
+
+	first := dtstart
+	switch r.Freq {
+	case Years:
+		first = time.Date(y, time.January, 1, hh, mm, ss, ns, loc)
+	case Months:
+		first = time.Date(y, m, 1, hh, mm, ss, ns, loc)
+	case Weeks:
+		weekStart := time.Monday
+		if r.WeekStart != nil {
+			weekStart = *r.WeekStart
+		}
+		first = time.Date(y, m, d-floorMod(int(dtstart.Weekday())-int(weekStart), 7), hh, mm, ss, ns, loc)
+	}
+	var step Duration
+	*step.field(r.Freq) = it.period * r.Interval
+	start := step.Shift(first)
+	it.period++
+
+	// Stop where time.Time and ISO 8601 years end, so that rules that never
+	// match don't loop forever, or where the step wrapped around.
+	if start.Year() > 9999 || start.Before(first) || (!r.Until.IsZero() && start.After(r.Until)) {
+		it.done = true
+		return
+	}
+
+	sy, sm, sd := start.Date()
+	days := 1
+	switch r.Freq {
+	case Years:
+		days = time.Date(sy, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	case Months:
+		days = daysIn(sy, sm)
+	case Weeks:
+		days = 7
+	}
+	for i := 0; i < days; i++ {
+		t := time.Date(sy, sm, sd+i, hh, mm, ss, ns, loc)
+		if r.matches(t) && !t.Before(dtstart) {
+			it.pending = append(it.pending, t)
+		}
+	}
+}
+
+// expandTime adds the next occurrence of an hourly, minutely or secondly
+// rule to the pending ones. Unlike longer periods, these are stepped from the
+// previous one, as counting them from dtstart soon overflows time.Duration.
+// The BYxxx rule parts only select days, so periods on days that don't match
+// are skipped at once.
+func (it *RRuleIterator) expandTime() {
+	r := it.r
+	unit := time.Hour
+	switch r.Freq {
+	case Minutes:
+		unit = time.Minute
+	case Seconds:
+		unit = time.Second
+	}
+	perDay := int(24 * time.Hour / unit)
+	// Steps of a day or longer are taken in days, which can't overflow.
+	days, step := r.Interval/perDay, time.Duration(r.Interval%perDay)*unit
+
+	for {
+		start := it.next
+		if start.Year() > 9999 || start.Before(it.dtstart) || (!r.Until.IsZero() && start.After(r.Until)) {
+			it.done = true
+			return
+		}
+		it.next = start.UTC().AddDate(0, 0, days).Add(step).In(start.Location())
+		if !it.next.After(start) {
+			// The step went out of the range of time.Time.
+			it.next = time.Time{}
+		}
+		if r.matches(start) {
+			it.pending = append(it.pending, start)
+			return
+		}
+		// Skip to the next day, or to the next month if this one doesn't
+		// match.
+		y, m, d := start.Date()
+		skipTo := time.Date(y, m, d+1, 0, 0, 0, 0, start.Location())
+		if !r.inMonth(m) {
+			skipTo = time.Date(y, m+1, 1, 0, 0, 0, 0, start.Location())
+		}
+		if days == 0 && it.next.Before(skipTo) {
+			n := (skipTo.Sub(start) + step - 1) / step
+			it.next = start.Add(n * step)
+		}
+	}
+}
+
+// inMonth reports whether m satisfies the BYMONTH rule part.
+func (r RRule) inMonth(m time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, bm := range r.ByMonth {
+		if bm == m {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether t satisfies the BYxxx rule parts.
+func (r RRule) matches(t time.Time) bool {
+	y, m, d := t.Date()
+	if !r.inMonth(m) {
+		return false
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		last := daysIn(y, m)
+		var ok bool
+		for _, md := range r.ByMonthDay {
+			ok = ok || md == d || (md < 0 && last+md+1 == d)
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	if len(r.ByDay) > 0 {
+		// Ordinals count within the year for yearly rules not limited to
+		// some months, and within the month otherwise.
+		pos, total := d, daysIn(y, m)
+		if r.Freq == Years && len(r.ByMonth) == 0 {
+			pos = t.YearDay()
+			total = time.Date(y, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		}
+		var ok bool
+		for _, w := range r.ByDay {
+			if w.Weekday != t.Weekday() {
+				continue
+			}
+			n := (pos-1)/7 + 1
+			if w.N < 0 {
+				n = -((total-pos)/7 + 1)
+			}
+			ok = ok || w.N == 0 || w.N == n
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
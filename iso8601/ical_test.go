@@ -0,0 +1,252 @@
+package iso8601
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanParseICalDuration(t *testing.T) {
+	cases := []struct {
+		from string
+		want Duration
+	}{
+		{"P15DT5H0M20S", Duration{D: 15, TH: 5, TS: 20}},
+		{"-P2W", Duration{W: 2, Negative: true}},
+		{"+PT1H", Duration{TH: 1}},
+		{"P1D", Duration{D: 1}},
+	}
+	for _, c := range cases {
+		got, err := ParseICalDuration(c.from)
+		if err != nil {
+			t.Fatalf("%s: %v", c.from, err)
+		}
+		if got != c.want {
+			t.Fatalf("%s: want=%+v, got=%+v", c.from, c.want, got)
+		}
+	}
+
+	for _, s := range []string{"P1Y", "P1M", "P0M", "PT1.5S", "P1W2D", "P1WT1H", "P0001-00-00", "P00010000", "P"} {
+		if _, err := ParseICalDuration(s); err == nil {
+			t.Fatalf("%q: expected error, got none", s)
+		}
+	}
+}
+
+func TestCanFormatICalDuration(t *testing.T) {
+	cases := []struct {
+		d    Duration
+		want string
+	}{
+		{Duration{W: 2}, "P2W"},
+		{Duration{W: 1, D: 1}, "P8D"},
+		{Duration{W: 1, TH: 1, Negative: true}, "-P7DT1H"},
+		{Duration{}, "P0D"},
+	}
+	for _, c := range cases {
+		got, err := FormatICalDuration(c.d)
+		if err != nil {
+			t.Fatalf("%+v: %v", c.d, err)
+		}
+		if got != c.want {
+			t.Fatalf("%+v: want=%s, got=%s", c.d, c.want, got)
+		}
+	}
+
+	for _, d := range []Duration{{Y: 1}, {M: 1}, {TS: 1, Frac: 5e8, FracUnit: Seconds}} {
+		if _, err := FormatICalDuration(d); err == nil {
+			t.Fatalf("%+v: expected error, got none", d)
+		}
+	}
+}
+
+func TestCanParseRRule(t *testing.T) {
+	r, err := ParseRRule("RRULE:FREQ=MONTHLY;INTERVAL=2;COUNT=6;BYDAY=-1FR,+1MO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Freq != Months || r.Interval != 2 || r.Count != 6 {
+		t.Fatalf("got %+v", r)
+	}
+	if len(r.ByDay) != 2 || r.ByDay[0] != (WeekdayNum{-1, time.Friday}) || r.ByDay[1] != (WeekdayNum{1, time.Monday}) {
+		t.Fatalf("got BYDAY %v", r.ByDay)
+	}
+
+	cases := []string{
+		"FREQ=MONTHLY;INTERVAL=2;COUNT=6;BYDAY=-1FR,1MO;BYMONTHDAY=1,-1;BYMONTH=1,6",
+		"FREQ=DAILY;UNTIL=19971224T000000Z",
+		"FREQ=WEEKLY;UNTIL=19971224;BYDAY=TU,TH",
+		"FREQ=SECONDLY",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,SU;WKST=SU",
+	}
+	for _, want := range cases {
+		r, err := ParseRRule(want)
+		if err != nil {
+			t.Fatalf("%s: %v", want, err)
+		}
+		if got := r.String(); got != want {
+			t.Fatalf("want=%s, got=%s", want, got)
+		}
+	}
+}
+
+func TestCanRejectBadRRule(t *testing.T) {
+	cases := []string{
+		"",
+		"COUNT=2",
+		"FREQ=FORTNIGHTLY",
+		"FREQ=DAILY;FREQ=DAILY",
+		"FREQ=DAILY;COUNT=2;UNTIL=20220101",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;INTERVAL=-1",
+		"FREQ=DAILY;UNTIL=2022-01",
+		"FREQ=DAILY;BYHOUR=1",
+		"FREQ=DAILY;COUNT",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=0MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=WEEKLY;WKST=1SU",
+		"FREQ=WEEKLY;WKST=XX",
+	}
+	for _, c := range cases {
+		if _, err := ParseRRule(c); err == nil {
+			t.Fatalf("%q: expected error, got none", c)
+		}
+	}
+}
+
+func TestRRuleIter(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sep2 := time.Date(1997, time.September, 2, 9, 0, 0, 0, ny)
+
+	cases := []struct {
+		rule    string
+		dtstart time.Time
+		limit   int
+		want    []string
+	}{
+		{
+			"FREQ=DAILY;COUNT=3", sep2, 0,
+			[]string{"1997-09-02T09:00:00-04:00", "1997-09-03T09:00:00-04:00", "1997-09-04T09:00:00-04:00"},
+		},
+		{
+			"FREQ=WEEKLY;INTERVAL=2;COUNT=6;BYDAY=TU,TH", sep2, 0,
+			[]string{
+				"1997-09-02T09:00:00-04:00", "1997-09-04T09:00:00-04:00",
+				"1997-09-16T09:00:00-04:00", "1997-09-18T09:00:00-04:00",
+				"1997-09-30T09:00:00-04:00", "1997-10-02T09:00:00-04:00",
+			},
+		},
+		// RFC 5545 gives these two to show how WKST changes the weeks.
+		{
+			"FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO", time.Date(1997, time.August, 5, 9, 0, 0, 0, ny), 0,
+			[]string{"1997-08-05T09:00:00-04:00", "1997-08-10T09:00:00-04:00", "1997-08-19T09:00:00-04:00", "1997-08-24T09:00:00-04:00"},
+		},
+		{
+			"FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU", time.Date(1997, time.August, 5, 9, 0, 0, 0, ny), 0,
+			[]string{"1997-08-05T09:00:00-04:00", "1997-08-17T09:00:00-04:00", "1997-08-19T09:00:00-04:00", "1997-08-31T09:00:00-04:00"},
+		},
+		{
+			"FREQ=MONTHLY;COUNT=4;BYDAY=1FR", time.Date(1997, time.September, 5, 9, 0, 0, 0, ny), 0,
+			[]string{
+				"1997-09-05T09:00:00-04:00", "1997-10-03T09:00:00-04:00",
+				"1997-11-07T09:00:00-05:00", "1997-12-05T09:00:00-05:00",
+			},
+		},
+		{
+			"FREQ=MONTHLY;BYDAY=-1FR", sep2, 2,
+			[]string{"1997-09-26T09:00:00-04:00", "1997-10-31T09:00:00-05:00"},
+		},
+		{
+			"FREQ=MONTHLY;COUNT=3", mustParseRFC3339(t, "2022-01-31T12:00:00Z"), 0,
+			[]string{"2022-01-31T12:00:00Z", "2022-03-31T12:00:00Z", "2022-05-31T12:00:00Z"},
+		},
+		{
+			"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1", mustParseRFC3339(t, "2023-02-28T00:00:00Z"), 3,
+			[]string{"2023-02-28T00:00:00Z", "2024-02-29T00:00:00Z", "2025-02-28T00:00:00Z"},
+		},
+		{
+			"FREQ=YEARLY;COUNT=2;BYDAY=20MO", mustParseRFC3339(t, "1997-01-01T09:00:00Z"), 0,
+			[]string{"1997-05-19T09:00:00Z", "1998-05-18T09:00:00Z"},
+		},
+		{
+			"FREQ=HOURLY;INTERVAL=3;UNTIL=19970902T170000Z", sep2, 0,
+			[]string{"1997-09-02T09:00:00-04:00", "1997-09-02T12:00:00-04:00"},
+		},
+		{
+			"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", mustParseRFC3339(t, "2022-01-01T00:00:00Z"), 0,
+			nil,
+		},
+		{
+			"FREQ=HOURLY;BYMONTH=2;BYMONTHDAY=30", mustParseRFC3339(t, "2022-01-31T00:00:00Z"), 0,
+			nil,
+		},
+		{
+			"FREQ=SECONDLY;INTERVAL=7;BYMONTHDAY=31;BYMONTH=4,6,9,11", mustParseRFC3339(t, "2022-01-31T00:00:00Z"), 0,
+			nil,
+		},
+		{
+			"FREQ=MINUTELY;INTERVAL=1500;COUNT=3;BYDAY=MO", mustParseRFC3339(t, "2022-05-15T23:00:00Z"), 0,
+			[]string{"2022-05-23T06:00:00Z", "2022-05-30T13:00:00Z", "2022-06-06T20:00:00Z"},
+		},
+		{
+			"FREQ=HOURLY;INTERVAL=2600000;COUNT=3", mustParseRFC3339(t, "2022-01-31T00:00:00Z"), 0,
+			[]string{"2022-01-31T00:00:00Z", "2318-09-10T08:00:00Z", "2615-04-19T16:00:00Z"},
+		},
+	}
+	for _, c := range cases {
+		r, err := ParseRRule(c.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", c.rule, err)
+		}
+		var got []time.Time
+		it := r.Iter(c.dtstart)
+		for c.limit == 0 || len(got) < c.limit {
+			occ, ok := it.Next()
+			if !ok {
+				break
+			}
+			got = append(got, occ)
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("%s: want %d occurrences, got %v", c.rule, len(c.want), got)
+		}
+		for i, w := range c.want {
+			if want := mustParseRFC3339(t, w); !want.Equal(got[i]) {
+				t.Fatalf("%s: occurrence %d: want=%s, got=%s", c.rule, i, want, got[i])
+			}
+		}
+	}
+}
+
+func TestRRuleIterUntil(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := ParseRRule("FREQ=DAILY;UNTIL=19971224T000000Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	it := r.Iter(time.Date(1997, time.September, 2, 9, 0, 0, 0, ny))
+	for {
+		occ, ok := it.Next()
+		if !ok {
+			break
+		}
+		if occ.Hour() != 9 {
+			t.Fatalf("want occurrences at 09:00 local time, got %s", occ)
+		}
+		n++
+	}
+	// RFC 5545 gives 113 occurrences for this rule.
+	if n != 113 {
+		t.Fatalf("want=113, got=%d", n)
+	}
+}
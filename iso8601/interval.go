@@ -0,0 +1,178 @@
+package iso8601
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Interval represents an ISO 8601 time interval. See
+// https://en.wikipedia.org/wiki/ISO_8601#Time_intervals.
+//
+// An interval is expressed in one of four forms:
+//
+//	<start>/<end>       2022-03-01T13:00:00Z/2022-05-11T15:30:00Z
+//	<start>/<duration>  2022-03-01T13:00:00Z/P1Y2M10DT2H30M
+//	<duration>/<end>    P1D/2022-05-01
+//	<duration>          P1Y2M10DT2H30M
+//
+// The last form has no anchor in time, so Start and End return the zero
+// time.Time for it and it contains no instants.
+//
+// The interval includes its start and excludes its end.
+type Interval struct {
+	start, end         time.Time
+	startPrec, endPrec Unit // as given when parsed, zero otherwise
+	dur                Duration
+	form               intervalForm
+}
+
+type intervalForm int
+
+const (
+	durationOnly intervalForm = iota
+	startEnd
+	startDuration
+	durationEnd
+)
+
+// NewInterval returns an interval between start and end.
+func NewInterval(start, end time.Time) Interval {
+	return Interval{start: start, end: end, form: startEnd}
+}
+
+// IntervalFrom returns an interval of duration d that begins at start.
+func IntervalFrom(start time.Time, d Duration) Interval {
+	return Interval{start: start, dur: d, form: startDuration}
+}
+
+// IntervalTo returns an interval of duration d that ends at end.
+func IntervalTo(d Duration, end time.Time) Interval {
+	return Interval{dur: d, end: end, form: durationEnd}
+}
+
+// ParseInterval parses an ISO 8601 time interval string in any of the four
+// forms described in the Interval documentation.
+//
+// Times are parsed with ParseTime, so they can have reduced precision, such
+// as 2022-05-01, which is interpreted as the start of the day in UTC.
+func ParseInterval(s string) (Interval, error) {
+	var i Interval
+	first, second, found := strings.Cut(s, "/")
+	if !found {
+		d, err := parseIntervalDuration(first)
+		if err != nil {
+			return i, err
+		}
+		return Interval{dur: d}, nil
+	}
+
+	if strings.HasPrefix(first, "P") {
+		d, err := parseIntervalDuration(first)
+		if err != nil {
+			return i, err
+		}
+		end, endPrec, err := ParseTime(second)
+		if err != nil {
+			return i, err
+		}
+		i = IntervalTo(d, end)
+		i.endPrec = endPrec
+		return i, nil
+	}
+
+	start, startPrec, err := ParseTime(first)
+	if err != nil {
+		return i, err
+	}
+	if strings.HasPrefix(second, "P") {
+		d, err := parseIntervalDuration(second)
+		if err != nil {
+			return i, err
+		}
+		i = IntervalFrom(start, d)
+		i.startPrec = startPrec
+		return i, nil
+	}
+	end, endPrec, err := ParseTime(second)
+	if err != nil {
+		return i, err
+	}
+	if end.Before(start) {
+		return i, errors.New("interval ends before it starts")
+	}
+	i = NewInterval(start, end)
+	i.startPrec, i.endPrec = startPrec, endPrec
+	return i, nil
+}
+
+func parseIntervalDuration(s string) (Duration, error) {
+	d, err := ParseDuration(s)
+	if err != nil {
+		return d, err
+	}
+	if d.Negative {
+		return d, errors.New("interval duration must not be negative")
+	}
+	return d, nil
+}
+
+// Start returns the start of the interval. For the <duration>/<end> form it
+// is computed by shifting the end backwards by the duration.
+func (i Interval) Start() time.Time {
+	switch i.form {
+	case startEnd, startDuration:
+		return i.start
+	case durationEnd:
+		back := i.dur
+		back.Negative = true
+		return back.Shift(i.end)
+	}
+	return time.Time{}
+}
+
+// End returns the end of the interval. For the <start>/<duration> form it is
+// computed by shifting the start by the duration.
+func (i Interval) End() time.Time {
+	switch i.form {
+	case startEnd, durationEnd:
+		return i.end
+	case startDuration:
+		return i.dur.Shift(i.start)
+	}
+	return time.Time{}
+}
+
+// Duration returns the duration of the interval. For the <start>/<end> form
+// it is the exact elapsed time expressed in hours, minutes and seconds.
+func (i Interval) Duration() Duration {
+	if i.form == startEnd {
+		return FromTimeDuration(i.end.Sub(i.start), Hours)
+	}
+	return i.dur
+}
+
+// Contains reports whether t falls within the interval.
+func (i Interval) Contains(t time.Time) bool {
+	if i.form == durationOnly {
+		return false
+	}
+	return !t.Before(i.Start()) && t.Before(i.End())
+}
+
+// String returns an ISO 8601 representation of the interval in the form it
+// was created with. Parsed times are written with the precision they were
+// given in.
+func (i Interval) String() string {
+	start := FormatTime(i.start, TimeLayout{Precision: i.startPrec})
+	end := FormatTime(i.end, TimeLayout{Precision: i.endPrec})
+	switch i.form {
+	case startEnd:
+		return start + "/" + end
+	case startDuration:
+		return start + "/" + i.dur.String()
+	case durationEnd:
+		return i.dur.String() + "/" + end
+	}
+	return i.dur.String()
+}
@@ -0,0 +1,135 @@
+package iso8601
+
+import (
+	"testing"
+	"time"
+)
+
+func mustParseRFC3339(t *testing.T, s string) time.Time {
+	t.Helper()
+	result, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestCanParseInterval(t *testing.T) {
+	cases := []struct {
+		from      string
+		wantStart string
+		wantEnd   string
+		wantDur   string
+	}{
+		{
+			"2022-03-01T13:00:00Z/2022-05-11T15:30:00Z",
+			"2022-03-01T13:00:00Z", "2022-05-11T15:30:00Z", "PT1706H30M",
+		},
+		{
+			"2022-03-01T13:00:00Z/P1Y2M10DT2H30M",
+			"2022-03-01T13:00:00Z", "2023-05-11T15:30:00Z", "P1Y2M10DT2H30M",
+		},
+		{
+			"P1D/2022-05-01",
+			"2022-04-30T00:00:00Z", "2022-05-01T00:00:00Z", "P1D",
+		},
+		{
+			"2022-05-01T00:00:00+03:00/PT1.5S",
+			"2022-05-01T00:00:00+03:00", "2022-05-01T00:00:01.5+03:00", "PT1.5S",
+		},
+	}
+
+	for _, c := range cases {
+		i, err := ParseInterval(c.from)
+		if err != nil {
+			t.Fatalf("%s: %v", c.from, err)
+		}
+		if want, got := mustParseRFC3339(t, c.wantStart), i.Start(); !want.Equal(got) {
+			t.Fatalf("%s: start: want=%s, got=%s", c.from, want, got)
+		}
+		if want, got := mustParseRFC3339(t, c.wantEnd), i.End(); !want.Equal(got) {
+			t.Fatalf("%s: end: want=%s, got=%s", c.from, want, got)
+		}
+		if got := i.Duration().String(); got != c.wantDur {
+			t.Fatalf("%s: duration: want=%s, got=%s", c.from, c.wantDur, got)
+		}
+	}
+}
+
+func TestCanParseDurationOnlyInterval(t *testing.T) {
+	i, err := ParseInterval("P1Y2M10DT2H30M")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !i.Start().IsZero() || !i.End().IsZero() {
+		t.Fatalf("want zero start and end, got %s and %s", i.Start(), i.End())
+	}
+	if want := (Duration{Y: 1, M: 2, D: 10, TH: 2, TM: 30}); i.Duration() != want {
+		t.Fatalf("want=%+v, got=%+v", want, i.Duration())
+	}
+	if i.Contains(time.Now()) {
+		t.Fatal("duration-only interval must not contain anything")
+	}
+}
+
+func TestCanRejectBadInterval(t *testing.T) {
+	cases := []string{
+		"",
+		"/",
+		"2022-03-01T13:00:00Z",
+		"2022-03-01T13:00:00Z/",
+		"P1D/P1D",
+		"2022-05-01/2022-04-01",
+		"-P1D/2022-05-01",
+		"2022-13-01/P1D",
+		"2022-03-01T13:00:00Z/2022-05-11T15:30:00Z/P1D",
+	}
+	for _, c := range cases {
+		if _, err := ParseInterval(c); err == nil {
+			t.Fatalf("%q: expected error, got none", c)
+		}
+	}
+}
+
+func TestIntervalContains(t *testing.T) {
+	i, err := ParseInterval("2022-03-01T00:00:00Z/P1M")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		t    string
+		want bool
+	}{
+		{"2022-02-28T23:59:59Z", false},
+		{"2022-03-01T00:00:00Z", true},
+		{"2022-03-15T12:00:00Z", true},
+		{"2022-03-31T23:59:59.999999999Z", true},
+		{"2022-04-01T00:00:00Z", false},
+	}
+	for _, c := range cases {
+		if got := i.Contains(mustParseRFC3339(t, c.t)); got != c.want {
+			t.Fatalf("%s: want=%v, got=%v", c.t, c.want, got)
+		}
+	}
+}
+
+func TestCanStringifyInterval(t *testing.T) {
+	cases := []string{
+		"2022-03-01T13:00:00Z/2022-05-11T15:30:00Z",
+		"2022-03-01T13:00:00Z/P1Y2M10DT2H30M",
+		"P1D/2022-05-01T00:00:00+03:00",
+		"P1D/2022-05-01",
+		"2022-05/P1M",
+		"2022-05-01T10:15Z/2022-05-01T12Z",
+		"P1Y2M10DT2H30M",
+	}
+	for _, want := range cases {
+		i, err := ParseInterval(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := i.String(); got != want {
+			t.Fatalf("want=%s, got=%s", want, got)
+		}
+	}
+}
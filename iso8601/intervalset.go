@@ -0,0 +1,203 @@
+package iso8601
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IntervalSet is a set of instants, kept as the fewest intervals that cover
+// them: sorted by start, with overlapping and adjacent intervals merged.
+// Like Interval, each interval includes its start and excludes its end.
+//
+// The zero value is an empty set.
+type IntervalSet struct {
+	spans []span
+}
+
+type span struct{ start, end time.Time }
+
+// NewIntervalSet returns the set of instants the intervals contain. Empty
+// and duration-only intervals contain nothing.
+func NewIntervalSet(intervals ...Interval) IntervalSet {
+	spans := make([]span, 0, len(intervals))
+	for _, i := range intervals {
+		if i.form == durationOnly {
+			continue
+		}
+		spans = append(spans, span{i.Start(), i.End()})
+	}
+	return normalize(spans)
+}
+
+// ParseIntervalSet parses the representation String returns: intervals in
+// any of the anchored forms ParseInterval accepts, separated by whitespace,
+// the way XML Schema separates list items.
+func ParseIntervalSet(s string) (IntervalSet, error) {
+	var intervals []Interval
+	for _, f := range strings.Fields(s) {
+		i, err := ParseInterval(f)
+		if err != nil {
+			return IntervalSet{}, err
+		}
+		if i.form == durationOnly {
+			return IntervalSet{}, errors.New("interval set can't have a duration-only interval")
+		}
+		intervals = append(intervals, i)
+	}
+	return NewIntervalSet(intervals...), nil
+}
+
+// normalize sorts spans, drops empty ones and merges those that overlap or
+// touch. It reuses the spans slice.
+func normalize(spans []span) IntervalSet {
+	n := 0
+	for _, sp := range spans {
+		if sp.start.Before(sp.end) {
+			spans[n] = sp
+			n++
+		}
+	}
+	spans = spans[:n]
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+
+	var merged []span
+	for _, sp := range spans {
+		if last := len(merged) - 1; last >= 0 && !sp.start.After(merged[last].end) {
+			if sp.end.After(merged[last].end) {
+				merged[last].end = sp.end
+			}
+			continue
+		}
+		merged = append(merged, sp)
+	}
+	return IntervalSet{spans: merged}
+}
+
+// Intervals returns the intervals of the set.
+func (s IntervalSet) Intervals() []Interval {
+	intervals := make([]Interval, len(s.spans))
+	for i, sp := range s.spans {
+		intervals[i] = NewInterval(sp.start, sp.end)
+	}
+	return intervals
+}
+
+// IsEmpty reports whether the set contains no instants.
+func (s IntervalSet) IsEmpty() bool { return len(s.spans) == 0 }
+
+// Contains reports whether t is in the set.
+func (s IntervalSet) Contains(t time.Time) bool {
+	i := sort.Search(len(s.spans), func(i int) bool { return s.spans[i].end.After(t) })
+	return i < len(s.spans) && !t.Before(s.spans[i].start)
+}
+
+// Union returns the set of instants in s or o.
+func (s IntervalSet) Union(o IntervalSet) IntervalSet {
+	spans := make([]span, 0, len(s.spans)+len(o.spans))
+	spans = append(spans, s.spans...)
+	return normalize(append(spans, o.spans...))
+}
+
+// Intersect returns the set of instants in both s and o.
+func (s IntervalSet) Intersect(o IntervalSet) IntervalSet {
+	var spans []span
+	for i, j := 0, 0; i < len(s.spans) && j < len(o.spans); {
+		a, b := s.spans[i], o.spans[j]
+		start, end := later(a.start, b.start), earlier(a.end, b.end)
+		if start.Before(end) {
+			spans = append(spans, span{start, end})
+		}
+		if a.end.Before(b.end) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return IntervalSet{spans: spans}
+}
+
+// Difference returns the set of instants in s, but not in o.
+func (s IntervalSet) Difference(o IntervalSet) IntervalSet {
+	var spans []span
+	j := 0
+	for _, sp := range s.spans {
+		for ; j < len(o.spans) && !o.spans[j].end.After(sp.start); j++ {
+		}
+		cur := sp.start
+		for k := j; k < len(o.spans) && o.spans[k].start.Before(sp.end); k++ {
+			if cur.Before(o.spans[k].start) {
+				spans = append(spans, span{cur, o.spans[k].start})
+			}
+			cur = later(cur, o.spans[k].end)
+		}
+		if cur.Before(sp.end) {
+			spans = append(spans, span{cur, sp.end})
+		}
+	}
+	return IntervalSet{spans: spans}
+}
+
+// Overlaps reports whether s and o have any instants in common.
+func (s IntervalSet) Overlaps(o IntervalSet) bool {
+	for i, j := 0, 0; i < len(s.spans) && j < len(o.spans); {
+		a, b := s.spans[i], o.spans[j]
+		if a.start.Before(b.end) && b.start.Before(a.end) {
+			return true
+		}
+		if a.end.Before(b.end) {
+			i++
+		} else {
+			j++
+		}
+	}
+	return false
+}
+
+// Gaps returns the set of instants between the intervals of s, that is,
+// from its first start to its last end, but not in s.
+func (s IntervalSet) Gaps() IntervalSet {
+	var spans []span
+	for i := 1; i < len(s.spans); i++ {
+		spans = append(spans, span{s.spans[i-1].end, s.spans[i].start})
+	}
+	return IntervalSet{spans: spans}
+}
+
+// Duration returns the total time covered by the set, expressed in hours,
+// minutes and seconds like the duration of a <start>/<end> interval.
+func (s IntervalSet) Duration() Duration {
+	var total time.Duration
+	for _, sp := range s.spans {
+		total += sp.end.Sub(sp.start)
+	}
+	return FromTimeDuration(total, Hours)
+}
+
+// String returns the intervals of the set in the <start>/<end> form,
+// separated by spaces. An empty set is an empty string.
+func (s IntervalSet) String() string {
+	var b strings.Builder
+	for i, in := range s.Intervals() {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(in.String())
+	}
+	return b.String()
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,185 @@
+package iso8601
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+func mustParseIntervalSet(t *testing.T, s string) IntervalSet {
+	t.Helper()
+	set, err := ParseIntervalSet(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func TestCanParseIntervalSet(t *testing.T) {
+	cases := []struct {
+		from string
+		want string
+	}{
+		{"", ""},
+		{
+			"2022-05-01T10:00:00Z/PT1H 2022-05-01T09:00:00Z/2022-05-01T10:00:00Z",
+			"2022-05-01T09:00:00Z/2022-05-01T11:00:00Z",
+		},
+		{
+			"2022-05-01T13:00:00Z/PT1H\n2022-05-01T09:00:00Z/PT2H  PT1H/2022-05-01T10:30:00Z",
+			"2022-05-01T09:00:00Z/2022-05-01T11:00:00Z 2022-05-01T13:00:00Z/2022-05-01T14:00:00Z",
+		},
+		{"2022-05-01T10:00:00Z/PT0S", ""},
+	}
+	for _, c := range cases {
+		if got := mustParseIntervalSet(t, c.from).String(); got != c.want {
+			t.Fatalf("%q: want=%q, got=%q", c.from, c.want, got)
+		}
+	}
+
+	for _, s := range []string{"P1D", "2022-05-01/P1D x", "2022-05-02/2022-05-01"} {
+		if _, err := ParseIntervalSet(s); err == nil {
+			t.Fatalf("%q: expected error, got none", s)
+		}
+	}
+}
+
+func TestIntervalSetAlgebra(t *testing.T) {
+	a := mustParseIntervalSet(t, "2022-05-01T09:00:00Z/2022-05-01T12:00:00Z 2022-05-01T14:00:00Z/2022-05-01T18:00:00Z")
+	b := mustParseIntervalSet(t, "2022-05-01T11:00:00Z/2022-05-01T15:00:00Z 2022-05-01T17:00:00Z/2022-05-01T17:30:00Z")
+
+	cases := []struct {
+		name string
+		got  IntervalSet
+		want string
+	}{
+		{"union", a.Union(b), "2022-05-01T09:00:00Z/2022-05-01T18:00:00Z"},
+		{"intersection", a.Intersect(b), "2022-05-01T11:00:00Z/2022-05-01T12:00:00Z 2022-05-01T14:00:00Z/2022-05-01T15:00:00Z 2022-05-01T17:00:00Z/2022-05-01T17:30:00Z"},
+		{"difference", a.Difference(b), "2022-05-01T09:00:00Z/2022-05-01T11:00:00Z 2022-05-01T15:00:00Z/2022-05-01T17:00:00Z 2022-05-01T17:30:00Z/2022-05-01T18:00:00Z"},
+		{"gaps", a.Gaps(), "2022-05-01T12:00:00Z/2022-05-01T14:00:00Z"},
+	}
+	for _, c := range cases {
+		if got := c.got.String(); got != c.want {
+			t.Fatalf("%s: want=%s, got=%s", c.name, c.want, got)
+		}
+	}
+
+	if got := a.Duration().String(); got != "PT7H" {
+		t.Fatalf("want=PT7H, got=%s", got)
+	}
+	if !a.Overlaps(b) {
+		t.Fatal("want overlap, got none")
+	}
+	if a.Overlaps(a.Gaps()) {
+		t.Fatal("set must not overlap its gaps")
+	}
+}
+
+// randomSet generates interval sets within a day, so that they often
+// overlap and touch.
+type randomSet struct{ IntervalSet }
+
+func (randomSet) Generate(r *rand.Rand, size int) reflect.Value {
+	base := time.Date(2022, time.May, 1, 0, 0, 0, 0, time.UTC)
+	intervals := make([]Interval, r.Intn(size+1))
+	for i := range intervals {
+		start := base.Add(time.Duration(r.Intn(24*60)) * time.Minute)
+		intervals[i] = NewInterval(start, start.Add(time.Duration(r.Intn(180))*time.Minute))
+	}
+	return reflect.ValueOf(randomSet{NewIntervalSet(intervals...)})
+}
+
+// probes returns the instants where the membership in the sets changes, and
+// those right before them.
+func probes(sets ...IntervalSet) []time.Time {
+	var ts []time.Time
+	for _, s := range sets {
+		for _, sp := range s.spans {
+			ts = append(ts, sp.start, sp.start.Add(-1), sp.end, sp.end.Add(-1))
+		}
+	}
+	return ts
+}
+
+func isNormal(s IntervalSet) bool {
+	for i, sp := range s.spans {
+		if !sp.start.Before(sp.end) {
+			return false
+		}
+		if i > 0 && !s.spans[i-1].end.Before(sp.start) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalSets(a, b IntervalSet) bool {
+	if len(a.spans) != len(b.spans) {
+		return false
+	}
+	for i := range a.spans {
+		if !a.spans[i].start.Equal(b.spans[i].start) || !a.spans[i].end.Equal(b.spans[i].end) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkProperty(t *testing.T, name string, f any) {
+	t.Helper()
+	if err := quick.Check(f, &quick.Config{MaxCount: 500}); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+}
+
+func TestIntervalSetProperties(t *testing.T) {
+	checkProperty(t, "membership", func(a, b randomSet) bool {
+		u, i, d := a.Union(b.IntervalSet), a.Intersect(b.IntervalSet), a.Difference(b.IntervalSet)
+		if !isNormal(u) || !isNormal(i) || !isNormal(d) {
+			return false
+		}
+		for _, p := range probes(a.IntervalSet, b.IntervalSet) {
+			inA, inB := a.Contains(p), b.Contains(p)
+			if u.Contains(p) != (inA || inB) || i.Contains(p) != (inA && inB) || d.Contains(p) != (inA && !inB) {
+				return false
+			}
+		}
+		return true
+	})
+
+	checkProperty(t, "commutativity", func(a, b randomSet) bool {
+		return equalSets(a.Union(b.IntervalSet), b.Union(a.IntervalSet)) &&
+			equalSets(a.Intersect(b.IntervalSet), b.Intersect(a.IntervalSet))
+	})
+
+	checkProperty(t, "overlap", func(a, b randomSet) bool {
+		return a.Overlaps(b.IntervalSet) == !a.Intersect(b.IntervalSet).IsEmpty()
+	})
+
+	checkProperty(t, "duration", func(a, b randomSet) bool {
+		sum, err := a.Union(b.IntervalSet).Duration().Add(a.Intersect(b.IntervalSet).Duration())
+		if err != nil {
+			return false
+		}
+		want, err := a.Duration().Add(b.Duration())
+		if err != nil {
+			return false
+		}
+		return sum.Compare(want, time.Time{}) == 0
+	})
+
+	checkProperty(t, "gaps", func(a randomSet) bool {
+		gaps := a.Gaps()
+		if a.Overlaps(gaps) || len(a.Union(gaps).spans) > 1 {
+			return false
+		}
+		return len(a.spans) == 0 || len(gaps.spans) == len(a.spans)-1
+	})
+
+	checkProperty(t, "round trip", func(a randomSet) bool {
+		back, err := ParseIntervalSet(a.String())
+		return err == nil && equalSets(back, a.IntervalSet)
+	})
+}
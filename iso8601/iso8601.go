@@ -1,16 +1,17 @@
 // Adapted from https://github.com/senseyeio/duration.
 
-// Package iso8601 handles ISO 8601-formatted durations.
+// Package iso8601 handles ISO 8601-formatted dates, times, durations and time
+// intervals.
 package iso8601
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"html/template"
-	"regexp"
+	"math"
+	"math/bits"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -24,62 +25,432 @@ type Duration struct {
 	TH int
 	TM int
 	TS int
+	// Fraction Component
+	//
+	// Frac is the decimal fraction of the FracUnit component in billionths, so
+	// PT1.5S is Duration{TS: 1, Frac: 500000000, FracUnit: Seconds}. FracUnit
+	// is meaningful only when Frac is non-zero.
+	Frac     int
+	FracUnit Unit
+	// Negative reports whether the duration is negative, as in -P1D. The
+	// components themselves are always non-negative.
+	Negative bool
 }
 
-var pattern = regexp.MustCompile(`^P((?P<year>\d+)Y)?((?P<month>\d+)M)?((?P<week>\d+)W)?((?P<day>\d+)D)?(T((?P<hour>\d+)H)?((?P<minute>\d+)M)?((?P<second>\d+)S)?)?$`)
+// Unit identifies a component of a Duration.
+type Unit int
+
+// Duration components, from the highest order to the lowest.
+const (
+	Years Unit = iota + 1
+	Months
+	Weeks
+	Days
+	Hours
+	Minutes
+	Seconds
+)
+
+var unitNames = [...]string{"years", "months", "weeks", "days", "hours", "minutes", "seconds"}
+
+// String returns the plural English name of the unit, such as "days".
+func (u Unit) String() string {
+	if u < Years || u > Seconds {
+		return "Unit(" + strconv.Itoa(int(u)) + ")"
+	}
+	return unitNames[u-1]
+}
+
+// fracDigits is the maximum number of fractional digits that Duration can
+// hold without losing precision.
+const fracDigits = 9
+
+// fracOne is a whole unit expressed in the scale of Duration.Frac.
+const fracOne = 1e9
+
+// ParseError describes a malformed duration string.
+//
+// Reason is a short description of the problem, such as "unexpected
+// character", "unexpected end", "out-of-order designator", "overflow" or
+// "empty time part".
+type ParseError struct {
+	Input  string // the string being parsed
+	Offset int    // byte offset of the problem in Input
+	Reason string
+	Err    error // the underlying error, such as ErrOverflow, if any
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("could not parse duration %q: %s at offset %d", e.Input, e.Reason, e.Offset)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
 
 // ParseDuration parses an ISO 8601 duration string.
-func ParseDuration(from string) (Duration, error) {
-	var match []string
-	var d Duration
+//
+// Both the format with designators, such as P3Y6M4DT12H30M5S, and the
+// alternative format in its extended (P0003-06-04T12:30:05) and basic
+// (P00030604T123005) forms are accepted. A duration must have at least one
+// component, and so must its time part if the T designator is present.
+//
+// Errors are of type *ParseError.
+func ParseDuration(s string) (Duration, error) {
+	return parseDuration(s, false)
+}
 
-	if pattern.MatchString(from) {
-		match = pattern.FindStringSubmatch(from)
-	} else {
-		return d, errors.New("could not parse duration string")
+// ParseDurationLenient is like ParseDuration, but also accepts lowercase
+// designators, surrounding whitespace and a bare P or PT, which is parsed as
+// a zero duration.
+func ParseDurationLenient(s string) (Duration, error) {
+	return parseDuration(s, true)
+}
+
+// durationParser holds the state of parsing a duration string.
+type durationParser struct {
+	input   string
+	pos     int // offset of the next byte to read
+	end     int // offset after the last byte to read
+	lenient bool
+}
+
+// peek returns the next byte, uppercased in lenient mode, or zero at the end.
+func (p *durationParser) peek() byte {
+	if p.pos == p.end {
+		return 0
+	}
+	c := p.input[p.pos]
+	if p.lenient && 'a' <= c && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	return c
+}
+
+func (p *durationParser) errorAt(off int, reason string, err error) *ParseError {
+	return &ParseError{Input: p.input, Offset: off, Reason: reason, Err: err}
+}
+
+// unexpected returns an error about the byte at the current offset.
+func (p *durationParser) unexpected() *ParseError {
+	if p.pos == p.end {
+		return p.errorAt(p.pos, "unexpected end", nil)
+	}
+	return p.errorAt(p.pos, "unexpected character", nil)
+}
+
+func parseDuration(input string, lenient bool) (Duration, error) {
+	p := durationParser{input: input, end: len(input), lenient: lenient}
+	if lenient {
+		for p.pos < p.end && isSpace(input[p.pos]) {
+			p.pos++
+		}
+		for p.end > p.pos && isSpace(input[p.end-1]) {
+			p.end--
+		}
 	}
 
-	for i, name := range pattern.SubexpNames() {
-		part := match[i]
-		if i == 0 || name == "" || part == "" {
+	var d Duration
+	if c := p.peek(); c == '-' || c == '+' {
+		d.Negative = c == '-'
+		p.pos++
+	}
+	if p.peek() != 'P' {
+		return Duration{}, p.unexpected()
+	}
+	p.pos++
+	if isAlternative(input[p.pos:p.end]) {
+		return p.alternative(d.Negative)
+	}
+
+	// next is the highest order unit that can follow, so that designators
+	// come in order and only once.
+	next, seen := Years, false
+	timeAt, fracAt := -1, -1
+	for p.pos < p.end {
+		if p.peek() == 'T' {
+			if timeAt >= 0 {
+				return Duration{}, p.unexpected()
+			}
+			timeAt, next = p.pos, Hours
+			p.pos++
 			continue
 		}
 
-		val, err := strconv.Atoi(part)
+		start := p.pos
+		n, frac, hasFrac, err := p.number()
 		if err != nil {
-			return d, err
-		}
-		switch name {
-		case "year":
-			d.Y = val
-		case "month":
-			d.M = val
-		case "week":
-			d.W = val
-		case "day":
-			d.D = val
-		case "hour":
-			d.TH = val
-		case "minute":
-			d.TM = val
-		case "second":
-			d.TS = val
-		default:
-			return d, fmt.Errorf("unknown field %s", name)
+			return Duration{}, err
+		}
+		u := designatorUnit(p.peek(), timeAt >= 0)
+		if u == 0 {
+			return Duration{}, p.unexpected()
+		}
+		if u < next {
+			return Duration{}, p.errorAt(p.pos, "out-of-order designator", nil)
 		}
+		// Only the lowest order component can have a decimal fraction.
+		if fracAt >= 0 {
+			return Duration{}, p.errorAt(start, "component after a fraction", nil)
+		}
+		p.pos++
+		next, seen = u+1, true
+
+		if hasFrac {
+			fracAt = start
+			if frac != 0 {
+				d.Frac = frac
+				d.FracUnit = u
+			}
+		}
+		*d.field(u) = n
 	}
 
+	if !seen && lenient {
+		return Duration{}, nil
+	}
+	if timeAt >= 0 && next <= Hours {
+		return Duration{}, p.errorAt(timeAt, "empty time part", nil)
+	}
+	if !seen {
+		return Duration{}, p.errorAt(p.pos, "empty duration", nil)
+	}
+	// -P0D is the same as P0D, and must be equal to it.
+	d.Negative = d.Negative && !d.IsZero()
 	return d, nil
 }
 
-// IsZero reports whether d represents the zero duration, P0D.
+// number scans digits with an optional decimal fraction. The fraction is
+// returned in billionths.
+func (p *durationParser) number() (n, frac int, hasFrac bool, err error) {
+	start := p.pos
+	for p.pos < p.end && isDigit(p.input[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, 0, false, p.unexpected()
+	}
+	if n, err = atoi(p.input[start:p.pos]); err != nil {
+		return 0, 0, false, p.errorAt(start, "overflow", err)
+	}
+	if c := p.peek(); c != '.' && c != ',' {
+		return n, 0, false, nil
+	}
+
+	p.pos++
+	start = p.pos
+	for p.pos < p.end && isDigit(p.input[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, 0, false, p.unexpected()
+	}
+	if frac, err = parseFrac(p.input[start:p.pos]); err != nil {
+		return 0, 0, false, p.errorAt(start, err.Error(), nil)
+	}
+	return n, frac, true, nil
+}
+
+// designatorUnit returns the unit for the designator c, or zero if there's
+// none.
+func designatorUnit(c byte, inTime bool) Unit {
+	if inTime {
+		switch c {
+		case 'H':
+			return Hours
+		case 'M':
+			return Minutes
+		case 'S':
+			return Seconds
+		}
+		return 0
+	}
+	switch c {
+	case 'Y':
+		return Years
+	case 'M':
+		return Months
+	case 'W':
+		return Weeks
+	case 'D':
+		return Days
+	}
+	return 0
+}
+
+// field returns a pointer to the component of d for the unit.
+func (d *Duration) field(u Unit) *int {
+	switch u {
+	case Years:
+		return &d.Y
+	case Months:
+		return &d.M
+	case Weeks:
+		return &d.W
+	case Days:
+		return &d.D
+	case Hours:
+		return &d.TH
+	case Minutes:
+		return &d.TM
+	}
+	return &d.TS
+}
+
+// atoi parses a non-empty string of ASCII digits.
+func atoi(s string) (int, error) {
+	var n int
+	for i := 0; i < len(s); i++ {
+		d := int(s[i] - '0')
+		if n > (math.MaxInt-d)/10 {
+			return 0, ErrOverflow
+		}
+		n = n*10 + d
+	}
+	return n, nil
+}
+
+func isSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+// altLimits holds the maximum values of the alternative format fields, which
+// must not exceed the carry-over points of the calendar.
+var altLimits = [...]struct {
+	name string
+	max  int
+}{
+	{"year", 9999},
+	{"month", 12},
+	{"day", 30},
+	{"hour", 24},
+	{"minute", 59},
+	{"second", 59},
+}
+
+// isAlternative reports whether s, the part of a duration string after P,
+// is in the alternative format.
+func isAlternative(s string) bool {
+	if len(s) < 8 || !isDigits(s[:4]) {
+		return false
+	}
+	if s[4] == '-' {
+		return true
+	}
+	return isDigits(s[4:8]) && (len(s) == 8 || s[8] == 'T' || s[8] == 't')
+}
+
+// alternative parses the rest of the input in the extended
+// (0003-06-04T12:30:05) or basic (00030604T123005) alternative format.
+func (p *durationParser) alternative(negative bool) (Duration, error) {
+	d := Duration{Negative: negative}
+	var dateSep, timeSep byte
+	if p.input[p.pos+4] == '-' {
+		dateSep, timeSep = '-', ':'
+	}
+
+	var vals [len(altLimits)]int
+	var hasTime bool
+	for i := range vals {
+		if i == 3 {
+			if p.pos == p.end {
+				break
+			}
+			if p.peek() != 'T' {
+				return Duration{}, p.unexpected()
+			}
+			p.pos++
+			hasTime = true
+		} else if i > 0 {
+			sep := dateSep
+			if i > 3 {
+				sep = timeSep
+			}
+			if sep != 0 {
+				if p.peek() != sep {
+					return Duration{}, p.unexpected()
+				}
+				p.pos++
+			}
+		}
+
+		width := 2
+		if i == 0 {
+			width = 4
+		}
+		start := p.pos
+		for p.pos < p.end && p.pos-start < width && isDigit(p.input[p.pos]) {
+			p.pos++
+		}
+		if p.pos-start < width {
+			return Duration{}, p.unexpected()
+		}
+		vals[i], _ = atoi(p.input[start:p.pos])
+		if vals[i] > altLimits[i].max {
+			return Duration{}, p.errorAt(start, altLimits[i].name+" out of range", nil)
+		}
+	}
+
+	if p.pos < p.end {
+		if c := p.peek(); !hasTime || (c != '.' && c != ',') {
+			return Duration{}, p.unexpected()
+		}
+		p.pos++
+		start := p.pos
+		for p.pos < p.end && isDigit(p.input[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start || p.pos < p.end {
+			return Duration{}, p.unexpected()
+		}
+		frac, err := parseFrac(p.input[start:p.pos])
+		if err != nil {
+			return Duration{}, p.errorAt(start, err.Error(), nil)
+		}
+		if frac != 0 {
+			d.Frac = frac
+			d.FracUnit = Seconds
+		}
+	}
+
+	d.Y, d.M, d.D, d.TH, d.TM, d.TS = vals[0], vals[1], vals[2], vals[3], vals[4], vals[5]
+	d.Negative = negative && !d.IsZero()
+	return d, nil
+}
+
+// cutFrac splits s around the decimal sign, which can be either a comma or a
+// full stop.
+func cutFrac(s string) (intPart, fracPart string, found bool) {
+	if i := strings.IndexAny(s, ".,"); i >= 0 {
+		return s[:i], s[i+1:], true
+	}
+	return s, "", false
+}
+
+// parseFrac parses the digits after the decimal sign into billionths.
+func parseFrac(s string) (int, error) {
+	s = strings.TrimRight(s, "0")
+	if len(s) > fracDigits {
+		return 0, fmt.Errorf("fraction has more than %d significant digits", fracDigits)
+	}
+	if s == "" {
+		return 0, nil
+	}
+	frac, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	for i := len(s); i < fracDigits; i++ {
+		frac *= 10
+	}
+	return frac, nil
+}
+
+// IsZero reports whether d represents the zero duration, P0D. The sign of a
+// zero duration is ignored.
 func (d Duration) IsZero() bool {
-	return d.Y == 0 && d.M == 0 && d.W == 0 && d.D == 0 && d.TH == 0 && d.TM == 0 && d.TS == 0
+	return d.Y == 0 && d.M == 0 && d.W == 0 && d.D == 0 && d.TH == 0 && d.TM == 0 && d.TS == 0 && d.Frac == 0
 }
 
 // HasTimePart returns true if the time part of the duration is non-zero.
 func (d Duration) HasTimePart() bool {
-	return d.TH > 0 || d.TM > 0 || d.TS > 0
+	return d.TH > 0 || d.TM > 0 || d.TS > 0 || (d.Frac != 0 && d.FracUnit >= Hours)
 }
 
 // Shift returns a time.Time, shifted by the duration from the given start.
@@ -87,16 +458,132 @@ func (d Duration) HasTimePart() bool {
 // NB: Shift uses time.AddDate for years, months, weeks, and days, and so
 // shares its limitations. In particular, shifting by months is not recommended
 // unless the start date is before the 28th of the month. Otherwise, dates will
-// roll over, e.g. Aug 31 + P1M = Oct 1.
+// roll over, e.g. Aug 31 + P1M = Oct 1. Use ShiftWith to choose another
+// behavior.
 //
 // Week and Day values will be combined as W*7 + D.
+//
+// A fraction of a year, month, week or day is applied after the integer
+// components, as the same fraction of the following unit of that length. For
+// example, shifting Jan 1 by P1.5M advances by one month to Feb 1 and then by
+// half of February.
+//
+// A negative duration moves t backwards: every component is subtracted in
+// the same order, so -P1M shifts Mar 15 to Feb 15.
 func (d Duration) Shift(t time.Time) time.Time {
+	// The default options never fail.
+	t, _ = d.ShiftWith(t, ShiftOptions{})
+	return t
+}
+
+// MonthEndPolicy selects what happens when shifting by years or months lands
+// on a day past the end of the month, such as Aug 31 + P1M.
+type MonthEndPolicy int
+
+const (
+	// MonthEndRollOver rolls the date over into the next month, as
+	// time.AddDate does: Aug 31 + P1M = Oct 1.
+	MonthEndRollOver MonthEndPolicy = iota
+	// MonthEndClamp moves the date back to the last day of the month:
+	// Aug 31 + P1M = Sep 30.
+	MonthEndClamp
+	// MonthEndError makes ShiftWith return an error wrapping ErrMonthEnd.
+	MonthEndError
+)
+
+// DSTPolicy selects how days, weeks, months and years are counted across
+// daylight saving time transitions.
+type DSTPolicy int
+
+const (
+	// DSTWallClock keeps the time of day: P1D from midnight is the next
+	// midnight, even if 23 or 25 hours pass.
+	DSTWallClock DSTPolicy = iota
+	// DSTElapsed counts absolute elapsed time: P1D is always 24 hours, so the
+	// time of day changes when crossing a transition.
+	DSTElapsed
+)
+
+// ShiftOptions control the calendar semantics of ShiftWith. The zero value
+// matches Shift.
+type ShiftOptions struct {
+	MonthEnd MonthEndPolicy
+	DST      DSTPolicy
+}
+
+// ErrMonthEnd is returned by ShiftWith with MonthEndError when a date falls
+// past the end of a month.
+var ErrMonthEnd = errors.New("date falls past the end of the month")
+
+// ShiftWith is like Shift, but lets the caller choose how month ends and DST
+// transitions are handled. It returns an error only with MonthEndError.
+func (d Duration) ShiftWith(t time.Time, opts ShiftOptions) (time.Time, error) {
+	loc := t.Location()
+	if opts.DST == DSTElapsed {
+		// Do the calendar arithmetic in a zone without transitions.
+		name, offset := t.Zone()
+		t = t.In(time.FixedZone(name, offset))
+	}
+
+	sign := d.sign()
 	if d.Y != 0 || d.M != 0 || d.W != 0 || d.D != 0 {
 		days := d.W*7 + d.D
-		t = t.AddDate(d.Y, d.M, days)
+		var err error
+		if t, err = addDate(t, sign*d.Y, sign*d.M, sign*days, opts.MonthEnd); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if d.Frac != 0 && d.FracUnit < Hours {
+		var next time.Time
+		var err error
+		switch d.FracUnit {
+		case Years:
+			next, err = addDate(t, sign, 0, 0, opts.MonthEnd)
+		case Months:
+			next, err = addDate(t, 0, sign, 0, opts.MonthEnd)
+		case Weeks:
+			next = t.AddDate(0, 0, sign*7)
+		case Days:
+			next = t.AddDate(0, 0, sign)
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+		t = t.Add(fracOf(next.Sub(t), d.Frac))
 	}
 	t = t.Add(d.timeDuration())
-	return t
+	if opts.DST == DSTElapsed {
+		t = t.In(loc)
+	}
+	return t, nil
+}
+
+// addDate is like time.AddDate, but handles the end of month according to
+// the policy.
+func addDate(t time.Time, years, months, days int, policy MonthEndPolicy) (time.Time, error) {
+	if policy == MonthEndRollOver || (years == 0 && months == 0) {
+		return t.AddDate(years, months, days), nil
+	}
+	year, month, day := t.Date()
+	// Let time.Date normalize the month, on the first day so it doesn't roll
+	// over.
+	first := time.Date(year+years, month+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	if last := daysIn(first.Year(), first.Month()); day > last {
+		if policy == MonthEndError {
+			return time.Time{}, fmt.Errorf("%w: %s %d, %d", ErrMonthEnd, first.Month(), day, first.Year())
+		}
+		day = last
+	}
+	hour, min, sec := t.Clock()
+	return time.Date(first.Year(), first.Month(), day+days, hour, min, sec, t.Nanosecond(), t.Location()), nil
+}
+
+// sign returns -1 if d is negative and 1 otherwise.
+func (d Duration) sign() int {
+	if d.Negative {
+		return -1
+	}
+	return 1
 }
 
 func (d Duration) timeDuration() time.Duration {
@@ -104,25 +591,565 @@ func (d Duration) timeDuration() time.Duration {
 	dur = dur + (time.Duration(d.TH) * time.Hour)
 	dur = dur + (time.Duration(d.TM) * time.Minute)
 	dur = dur + (time.Duration(d.TS) * time.Second)
+	if d.Frac != 0 {
+		switch d.FracUnit {
+		case Hours:
+			dur = dur + fracOf(time.Hour, d.Frac)
+		case Minutes:
+			dur = dur + fracOf(time.Minute, d.Frac)
+		case Seconds:
+			dur = dur + fracOf(time.Second, d.Frac)
+		}
+	}
+	if d.Negative {
+		dur = -dur
+	}
 	return dur
 }
 
-var tmpl = template.Must(template.New("duration").Parse(`P{{if .Y}}{{.Y}}Y{{end}}{{if .M}}{{.M}}M{{end}}{{if .W}}{{.W}}W{{end}}{{if .D}}{{.D}}D{{end}}{{if .HasTimePart}}T{{end }}{{if .TH}}{{.TH}}H{{end}}{{if .TM}}{{.TM}}M{{end}}{{if .TS}}{{.TS}}S{{end}}`))
+// fracOf returns frac billionths of the span.
+func fracOf(span time.Duration, frac int) time.Duration {
+	neg := span < 0
+	if neg {
+		span = -span
+	}
+	hi, lo := bits.Mul64(uint64(span), uint64(frac))
+	q, _ := bits.Div64(hi, lo, fracOne)
+	if neg {
+		return -time.Duration(q)
+	}
+	return time.Duration(q)
+}
+
+// designators holds the designator of every unit.
+var designators = [Seconds]byte{'Y', 'M', 'W', 'D', 'H', 'M', 'S'}
+
+// fracString formats billionths as decimal digits without trailing zeros.
+func fracString(frac int) string {
+	var buf [fracDigits + 1]byte
+	return string(appendFrac(buf[:0], frac))
+}
+
+// appendFrac appends billionths as decimal digits without trailing zeros.
+func appendFrac(b []byte, frac int) []byte {
+	var digits [fracDigits]byte
+	n := len(digits)
+	for i := len(digits) - 1; i >= 0; i-- {
+		digits[i] = byte('0' + frac%10)
+		frac /= 10
+		if digits[i] == '0' && n == i+1 {
+			n = i
+		}
+	}
+	return append(b, digits[:n]...)
+}
 
 // String returns an ISO 8601-ish representation of the duration.
 func (d Duration) String() string {
-	var s bytes.Buffer
-
 	if d.IsZero() {
 		return "P0D"
 	}
 
-	err := tmpl.Execute(&s, d)
+	var buf [64]byte
+	b := buf[:0]
+	if d.Negative {
+		b = append(b, '-')
+	}
+	b = append(b, 'P')
+	for u := Years; u <= Seconds; u++ {
+		if u == Hours {
+			if !d.HasTimePart() {
+				break
+			}
+			b = append(b, 'T')
+		}
+		n := *d.field(u)
+		hasFrac := d.Frac != 0 && d.FracUnit == u
+		if n == 0 && !hasFrac {
+			continue
+		}
+		b = strconv.AppendInt(b, int64(n), 10)
+		if hasFrac {
+			b = append(b, '.')
+			b = appendFrac(b, d.Frac)
+		}
+		b = append(b, designators[u-1])
+	}
+	return string(b)
+}
+
+// Notation is a way of writing a Duration.
+type Notation int
+
+const (
+	// DesignatorNotation is the format with designators, such as
+	// P3Y6M4DT12H30M5S. It's what String returns.
+	DesignatorNotation Notation = iota
+	// ExtendedNotation is the alternative format in the extended form, such as
+	// P0003-06-04T12:30:05.
+	ExtendedNotation
+	// BasicNotation is the alternative format in the basic form, such as
+	// P00030604T123005.
+	BasicNotation
+)
+
+// FormatDuration returns a representation of d in the given notation.
+//
+// The alternative format has no place for weeks, so they are written as
+// seven days each. It returns an error if a component exceeds its carry-over
+// point (for example, more than 30 days) or if a component other than seconds
+// has a fraction.
+func FormatDuration(d Duration, n Notation) (string, error) {
+	switch n {
+	case DesignatorNotation:
+		return d.String(), nil
+	case ExtendedNotation, BasicNotation:
+	default:
+		return "", fmt.Errorf("unknown notation %d", n)
+	}
+
+	if d.Frac != 0 && d.FracUnit != Seconds {
+		return "", errors.New("alternative format allows a fraction of seconds only")
+	}
+	vals := [len(altLimits)]int{d.Y, d.M, d.W*7 + d.D, d.TH, d.TM, d.TS}
+	for i, val := range vals {
+		if val < 0 || val > altLimits[i].max {
+			return "", fmt.Errorf("%s %d out of range for alternative format", altLimits[i].name, val)
+		}
+	}
+
+	var buf [32]byte
+	b := buf[:0]
+	if d.Negative && !d.IsZero() {
+		b = append(b, '-')
+	}
+	b = append(b, 'P')
+	for i, val := range vals {
+		width := 2
+		switch {
+		case i == 0:
+			width = 4
+		case i == 3:
+			b = append(b, 'T')
+		case n == ExtendedNotation && i < 3:
+			b = append(b, '-')
+		case n == ExtendedNotation:
+			b = append(b, ':')
+		}
+		b = appendPadded(b, val, width)
+	}
+	if d.Frac != 0 {
+		b = append(b, '.')
+		b = appendFrac(b, d.Frac)
+	}
+	return string(b), nil
+}
+
+// appendPadded appends the non-negative n padded with zeros to the width.
+func appendPadded(b []byte, n, width int) []byte {
+	digits := 1
+	for m := n / 10; m > 0; m /= 10 {
+		digits++
+	}
+	for ; digits < width; digits++ {
+		b = append(b, '0')
+	}
+	return strconv.AppendInt(b, int64(n), 10)
+}
+
+// Between returns the duration between a and b, so that Between(a, b).Shift(a)
+// equals b. If b is before a, the duration is negative.
+//
+// The duration is counted in whole years and months first, then whole days
+// and then the remaining hours, minutes and seconds, all on a's wall clock:
+// Between(Mar 12, Mar 13) in America/New_York is P1D even though only 23
+// hours pass. Since Shift rolls dates over the end of a month, so does
+// Between: Between(Jan 31, Mar 1, 2022) is P29D, as Jan 31 + P1M is already
+// Mar 3.
+//
+// Days are never combined into weeks.
+func Between(a, b time.Time) Duration {
+	b = b.In(a.Location())
+	sign := 1
+	if b.Before(a) {
+		sign = -1
+	}
+	past := func(t time.Time) bool {
+		if sign > 0 {
+			return t.After(b)
+		}
+		return t.Before(b)
+	}
+
+	months := sign * ((b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month()))
+	if months < 0 {
+		months = 0
+	}
+	for months > 0 && past(a.AddDate(0, sign*months, 0)) {
+		months--
+	}
+	for !past(a.AddDate(0, sign*(months+1), 0)) {
+		months++
+	}
+
+	days := sign * int(b.Sub(a.AddDate(0, sign*months, 0))/(24*time.Hour))
+	if days < 0 {
+		days = 0
+	}
+	for days > 0 && past(a.AddDate(0, sign*months, sign*days)) {
+		days--
+	}
+	for !past(a.AddDate(0, sign*months, sign*(days+1))) {
+		days++
+	}
+
+	d := FromTimeDuration(time.Duration(sign)*b.Sub(a.AddDate(0, sign*months, sign*days)), Hours)
+	d.Y, d.M, d.D = months/12, months%12, days
+	d.Negative = sign < 0 && !d.IsZero()
+	return d
+}
+
+// ErrAmbiguous is returned by TimeDuration when a duration with calendar
+// components is converted without a reference time.
+var ErrAmbiguous = errors.New("calendar components of a duration need a reference time")
+
+// TimeDuration returns the exact time that passes when shifting ref by d.
+//
+// Years, months, weeks and days have no fixed length, so they are measured
+// from ref in its location: P1D is 23 hours long on the day DST starts. If d
+// has any of them and ref is the zero time.Time, TimeDuration returns
+// ErrAmbiguous. If the result doesn't fit into a time.Duration, it returns
+// ErrOverflow.
+func (d Duration) TimeDuration(ref time.Time) (time.Duration, error) {
+	calendar := d.Y != 0 || d.M != 0 || d.W != 0 || d.D != 0 || (d.Frac != 0 && d.FracUnit < Hours)
+	if !calendar {
+		td, ok := d.checkedTimeDuration()
+		if !ok {
+			return 0, ErrOverflow
+		}
+		return td, nil
+	}
+	if ref.IsZero() {
+		return 0, ErrAmbiguous
+	}
+	if _, ok := d.checkedTimeDuration(); !ok {
+		return 0, ErrOverflow
+	}
+	end := d.Shift(ref)
+	td := end.Sub(ref)
+	if !ref.Add(td).Equal(end) {
+		return 0, ErrOverflow
+	}
+	return td, nil
+}
+
+// checkedTimeDuration is like timeDuration, but reports whether the result
+// didn't overflow.
+func (d Duration) checkedTimeDuration() (time.Duration, bool) {
+	var sum int
+	for _, c := range [...]struct {
+		unit Unit
+		size time.Duration
+		n    int
+	}{
+		{Hours, time.Hour, d.TH},
+		{Minutes, time.Minute, d.TM},
+		{Seconds, time.Second, d.TS},
+	} {
+		n, ok := mulInt(d.sign()*c.n, int(c.size))
+		if !ok {
+			return 0, false
+		}
+		if d.Frac != 0 && d.FracUnit == c.unit {
+			if n, ok = addInt(n, d.sign()*int(fracOf(c.size, d.Frac))); !ok {
+				return 0, false
+			}
+		}
+		if sum, ok = addInt(sum, n); !ok {
+			return 0, false
+		}
+	}
+	return time.Duration(sum), true
+}
+
+// FromTimeDuration balances td into a Duration with components no larger
+// than the given unit, which is one of Weeks, Days, Hours, Minutes or
+// Seconds. A week is seven days of 24 hours. Nanoseconds are kept as a
+// fraction of a second.
+//
+// For example, FromTimeDuration(26*time.Hour, Days) is P1DT2H and
+// FromTimeDuration(26*time.Hour, Hours) is PT26H.
+func FromTimeDuration(td time.Duration, largest Unit) Duration {
+	var d Duration
+	// Work with the magnitude as uint64 so that math.MinInt64 works too.
+	rest := uint64(td)
+	if td < 0 {
+		d.Negative = true
+		rest = -rest
+	}
+	for _, c := range []struct {
+		unit Unit
+		size time.Duration
+		n    *int
+	}{
+		{Weeks, 7 * 24 * time.Hour, &d.W},
+		{Days, 24 * time.Hour, &d.D},
+		{Hours, time.Hour, &d.TH},
+		{Minutes, time.Minute, &d.TM},
+		{Seconds, time.Second, &d.TS},
+	} {
+		if c.unit < largest {
+			continue
+		}
+		*c.n = int(rest / uint64(c.size))
+		rest %= uint64(c.size)
+	}
+	if rest != 0 {
+		d.Frac = int(rest)
+		d.FracUnit = Seconds
+	}
+	return d
+}
+
+// Normalize returns d with seconds carried into minutes, minutes into hours
+// and months into years, so that PT90M and PT1H30M both become PT1H30M. A
+// fraction of an hour or a minute is moved down to seconds. If weeks is true,
+// days are carried into weeks as well.
+//
+// Hours are never carried into days, nor days into months, since those have
+// no fixed length. The result shifts any time exactly as d does.
+func (d Duration) Normalize(weeks bool) Duration {
+	neg := d.Negative
+	d.Negative = false
+	c := d.comps()
+
+	for _, u := range []Unit{Hours, Minutes} {
+		if c[u-1].f != 0 {
+			c[u].f += c[u-1].f * lowerRatio[u-1]
+			c[u-1].f = 0
+			carry(&c[u])
+		}
+	}
+	carryInto(&c[Minutes-1], &c[Seconds-1], 60)
+	carryInto(&c[Hours-1], &c[Minutes-1], 60)
+	carryInto(&c[Years-1], &c[Months-1], 12)
+	if weeks {
+		carryInto(&c[Weeks-1], &c[Days-1], 7)
+	}
+
+	n, err := fromComps(c)
 	if err != nil {
-		panic(err)
+		// Only a duration with a fraction of a month followed by lower order
+		// components gets here, and there's nothing to do for it.
+		d.Negative = neg
+		return d
+	}
+	if neg {
+		n = n.Neg()
+	}
+	return n
+}
+
+// carryInto moves whole multiples of size from lower into higher, unless that
+// would overflow.
+func carryInto(higher, lower *comp, size int) {
+	if sum, ok := addInt(higher.n, lower.n/size); ok {
+		higher.n = sum
+		lower.n %= size
+	}
+}
+
+// Compare compares the times d and e shift anchor to. It returns -1 if d is
+// shorter than e, 0 if they're equal and +1 if d is longer.
+//
+// Years, months, weeks and days have no fixed length, so the result depends on
+// the anchor: P1M is shorter than P30D from Feb 1, but longer from Mar 1.
+func (d Duration) Compare(e Duration, anchor time.Time) int {
+	a, b := d.Shift(anchor), e.Shift(anchor)
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// ErrOverflow is returned when the result of an operation on durations
+// doesn't fit into a Duration.
+var ErrOverflow = errors.New("duration overflows")
+
+// Neg returns the duration with the opposite sign.
+func (d Duration) Neg() Duration {
+	if d.IsZero() {
+		return Duration{}
+	}
+	d.Negative = !d.Negative
+	return d
+}
+
+// Add returns the component-wise sum of d and e: years are added to years,
+// months to months and so on, without carrying between components.
+//
+// Shifting by a.Add(b) gives the same time as shifting by a and then by b,
+// provided that both have the same sign and that shifting by a doesn't roll
+// the date over the end of a month or land in a DST transition. For example,
+// from Jan 31, 2022, P1D and then P1M gives Mar 1 (Jan 32 rolls over to Feb 1),
+// but P1M1D gives Mar 4 (Feb 32 rolls over to Mar 4).
+//
+// A fraction of a higher order component is carried into the lower order ones
+// when the sum has both, for example P0.5D + PT1H = PT13H. Add returns an
+// error if that is impossible, as with a fraction of a month, or if the
+// components of the sum have different signs, as in P1M - P1D, since ISO 8601
+// has no way to write such a duration. It returns ErrOverflow if a component
+// overflows.
+func (d Duration) Add(e Duration) (Duration, error) {
+	a, b := d.comps(), e.comps()
+	for i := range a {
+		var ok bool
+		if a[i].n, ok = addInt(a[i].n, b[i].n); !ok {
+			return Duration{}, ErrOverflow
+		}
+		// Fractions are less than fracOne in magnitude, so this can't
+		// overflow.
+		a[i].f += b[i].f
+	}
+	return fromComps(a)
+}
+
+// Sub returns the component-wise difference of d and e. See Add for details.
+func (d Duration) Sub(e Duration) (Duration, error) {
+	return d.Add(e.Neg())
+}
+
+// Scale returns d with every component multiplied by n. It returns
+// ErrOverflow if a component overflows.
+func (d Duration) Scale(n int) (Duration, error) {
+	c := d.comps()
+	for i := range c {
+		var ok bool
+		if c[i].n, ok = mulInt(c[i].n, n); !ok {
+			return Duration{}, ErrOverflow
+		}
+		if c[i].f, ok = mulInt(c[i].f, n); !ok {
+			return Duration{}, ErrOverflow
+		}
+	}
+	return fromComps(c)
+}
+
+// comp is a signed component of a duration with its fraction in billionths.
+type comp struct{ n, f int }
+
+// comps returns the signed components of d, indexed by Unit-1.
+func (d Duration) comps() [Seconds]comp {
+	s := d.sign()
+	c := [Seconds]comp{
+		{n: s * d.Y}, {n: s * d.M}, {n: s * d.W}, {n: s * d.D},
+		{n: s * d.TH}, {n: s * d.TM}, {n: s * d.TS},
+	}
+	if d.Frac != 0 && d.FracUnit >= Years && d.FracUnit <= Seconds {
+		c[d.FracUnit-1].f = s * d.Frac
+	}
+	return c
+}
+
+// lowerRatio holds how many of the next lower order unit make up each unit, or
+// zero if that number varies.
+var lowerRatio = [Seconds]int{12, 0, 7, 24, 60, 60, 0}
+
+// fromComps builds a Duration from signed components, carrying whole units
+// out of the fractions and fractions down to the lowest order component.
+func fromComps(c [Seconds]comp) (Duration, error) {
+	for i := range c {
+		if err := carry(&c[i]); err != nil {
+			return Duration{}, err
+		}
+		if c[i].f == 0 || !hasLower(c, i) {
+			continue
+		}
+		if lowerRatio[i] == 0 {
+			return Duration{}, fmt.Errorf("can't carry a fraction of %s into lower order components", Unit(i+1))
+		}
+		// Both factors are small, so this can't overflow.
+		c[i+1].f += c[i].f * lowerRatio[i]
+		c[i].f = 0
+	}
+
+	var d Duration
+	var pos, neg bool
+	for i := range c {
+		pos = pos || c[i].n > 0 || c[i].f > 0
+		neg = neg || c[i].n < 0 || c[i].f < 0
+		if c[i].f != 0 {
+			d.FracUnit = Unit(i + 1)
+		}
+	}
+	if pos && neg {
+		return Duration{}, errors.New("components of a duration must have the same sign")
+	}
+	for i := range c {
+		if c[i].n == math.MinInt {
+			return Duration{}, ErrOverflow
+		}
+		if neg {
+			c[i].n, c[i].f = -c[i].n, -c[i].f
+		}
+	}
+	d.Y, d.M, d.W, d.D = c[0].n, c[1].n, c[2].n, c[3].n
+	d.TH, d.TM, d.TS = c[4].n, c[5].n, c[6].n
+	if d.FracUnit != 0 {
+		d.Frac = c[d.FracUnit-1].f
+	}
+	d.Negative = neg
+	return d, nil
+}
+
+// carry moves whole units out of the fraction of c and gives the fraction
+// the same sign as the whole part.
+func carry(c *comp) error {
+	var ok bool
+	if c.n, ok = addInt(c.n, c.f/fracOne); !ok {
+		return ErrOverflow
+	}
+	c.f %= fracOne
+	switch {
+	case c.n > 0 && c.f < 0:
+		c.n, c.f = c.n-1, c.f+fracOne
+	case c.n < 0 && c.f > 0:
+		c.n, c.f = c.n+1, c.f-fracOne
+	}
+	return nil
+}
+
+// hasLower reports whether any component of lower order than i is non-zero.
+func hasLower(c [Seconds]comp, i int) bool {
+	for _, l := range c[i+1:] {
+		if l.n != 0 || l.f != 0 {
+			return true
+		}
 	}
+	return false
+}
 
-	return s.String()
+// addInt returns a+b and reports whether it didn't overflow.
+func addInt(a, b int) (int, bool) {
+	if (b > 0 && a > math.MaxInt-b) || (b < 0 && a < math.MinInt-b) {
+		return 0, false
+	}
+	return a + b, true
+}
+
+// mulInt returns a*b and reports whether it didn't overflow.
+func mulInt(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt) || (b == -1 && a == math.MinInt) {
+		return 0, false
+	}
+	return c, true
 }
 
 // MarshalJSON satisfies json.Marshaler.
@@ -2,6 +2,8 @@ package iso8601
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"testing"
 	"time"
 )
@@ -55,6 +57,533 @@ func TestCanShift(t *testing.T) {
 	}
 }
 
+func TestCanShiftFraction(t *testing.T) {
+	cases := []struct {
+		from     string
+		duration string
+		want     string
+	}{
+		{"Jan 1, 2018 at 00:00:00", "PT1.5M", "Jan 1, 2018 at 00:01:30"},
+		{"Jan 1, 2018 at 00:00:00", "PT0.25H", "Jan 1, 2018 at 00:15:00"},
+		{"Jan 1, 2018 at 00:00:00", "P0.5D", "Jan 1, 2018 at 12:00:00"},
+		{"Jan 1, 2018 at 00:00:00", "P1.5W", "Jan 11, 2018 at 12:00:00"},
+		{"Jan 1, 2018 at 00:00:00", "P1.5M", "Feb 15, 2018 at 00:00:00"},
+		{"Jan 1, 2018 at 00:00:00", "P0.5Y", "Jul 2, 2018 at 12:00:00"},
+	}
+
+	for k, c := range cases {
+		from := makeTime(t, c.from)
+		want := makeTime(t, c.want)
+		d, err := ParseDuration(c.duration)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := d.Shift(from)
+		if !want.Equal(got) {
+			t.Fatalf("Case %d: want=%s, got=%s", k, want, got)
+		}
+	}
+
+	d, err := ParseDuration("PT1.5S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := makeTime(t, "Jan 1, 2018 at 00:00:00")
+	if got, want := d.Shift(from).Sub(from), 1500*time.Millisecond; got != want {
+		t.Fatalf("want=%s, got=%s", want, got)
+	}
+}
+
+func TestCanShiftBackwards(t *testing.T) {
+	cases := []struct {
+		from     string
+		duration string
+		want     string
+	}{
+		{"Mar 15, 2018 at 00:00:00", "-P1M", "Feb 15, 2018 at 00:00:00"},
+		{"Jan 1, 2018 at 00:00:00", "-P1D", "Dec 31, 2017 at 00:00:00"},
+		{"Jan 1, 2018 at 06:00:00", "-PT30M", "Jan 1, 2018 at 05:30:00"},
+		{"Jan 1, 2018 at 06:00:00", "-P1Y1DT1H", "Dec 31, 2016 at 05:00:00"},
+		{"Jan 1, 2018 at 00:00:00", "-P0.5D", "Dec 31, 2017 at 12:00:00"},
+	}
+
+	for k, c := range cases {
+		from := makeTime(t, c.from)
+		want := makeTime(t, c.want)
+		d, err := ParseDuration(c.duration)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := d.Shift(from)
+		if !want.Equal(got) {
+			t.Fatalf("Case %d: want=%s, got=%s", k, want, got)
+		}
+	}
+}
+
+func TestCanAdd(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want string
+	}{
+		{"P1Y", "P1Y", "P2Y"},
+		{"P1Y2M", "P3W4D", "P1Y2M3W4D"},
+		{"PT50M", "PT20M", "PT70M"},
+		{"P1DT1H", "-PT1H", "P1D"},
+		{"-P1D", "-PT1H", "-P1DT1H"},
+		{"-P1D", "P1D", "P0D"},
+		{"PT0.5S", "PT0.75S", "PT1.25S"},
+		{"PT1.5S", "-PT0.75S", "PT0.75S"},
+		{"P0.5D", "PT1H", "PT13H"},
+		{"P0.5Y", "P1D", "P6M1D"},
+		{"P1.5W", "P1D", "P1W4.5D"},
+		{"PT0.5H", "PT0.5M", "PT30.5M"},
+	}
+
+	for _, c := range cases {
+		a, err := ParseDuration(c.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ParseDuration(c.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := a.Add(b)
+		if err != nil {
+			t.Fatalf("%s + %s: %v", c.a, c.b, err)
+		}
+		if got.String() != c.want {
+			t.Fatalf("%s + %s: want=%s, got=%s", c.a, c.b, c.want, got)
+		}
+	}
+}
+
+func TestCanSub(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want string
+	}{
+		{"P2Y", "P1Y", "P1Y"},
+		{"P1Y", "P2Y", "-P1Y"},
+		{"P1DT1H", "PT1H", "P1D"},
+		{"PT1.25S", "PT0.75S", "PT0.5S"},
+		{"-P1D", "-P1D", "P0D"},
+	}
+
+	for _, c := range cases {
+		a, err := ParseDuration(c.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ParseDuration(c.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := a.Sub(b)
+		if err != nil {
+			t.Fatalf("%s - %s: %v", c.a, c.b, err)
+		}
+		if got.String() != c.want {
+			t.Fatalf("%s - %s: want=%s, got=%s", c.a, c.b, c.want, got)
+		}
+	}
+}
+
+func TestCanShiftBySum(t *testing.T) {
+	cases := []struct {
+		from string
+		a, b Duration
+	}{
+		{"Jan 1, 2018 at 00:00:00", Duration{Y: 1}, Duration{M: 5}},
+		{"Jan 1, 2018 at 00:00:00", Duration{M: 1}, Duration{D: 30}},
+		{"Jan 15, 2018 at 00:00:00", Duration{M: 1, W: 1}, Duration{D: 3, TH: 4}},
+		{"Jan 31, 2018 at 00:00:00", Duration{TH: 5}, Duration{TM: 70, TS: 3}},
+		{"Mar 15, 2018 at 00:00:00", Duration{M: 1, Negative: true}, Duration{D: 2, Negative: true}},
+	}
+
+	for k, c := range cases {
+		from := makeTime(t, c.from)
+		sum, err := c.a.Add(c.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := c.b.Shift(c.a.Shift(from))
+		if got := sum.Shift(from); !want.Equal(got) {
+			t.Fatalf("Case %d: want=%s, got=%s", k, want, got)
+		}
+	}
+}
+
+func TestCanRejectBadAdd(t *testing.T) {
+	cases := []struct {
+		a, b Duration
+	}{
+		{Duration{M: 1}, Duration{D: 1, Negative: true}},
+		{Duration{M: 1, Frac: 500000000, FracUnit: Months}, Duration{D: 1}},
+		{Duration{Y: math.MaxInt}, Duration{Y: 1}},
+		{Duration{TS: math.MaxInt, Frac: 900000000, FracUnit: Seconds}, Duration{Frac: 200000000, FracUnit: Seconds}},
+	}
+	for _, c := range cases {
+		if got, err := c.a.Add(c.b); err == nil {
+			t.Fatalf("%+v + %+v: expected error, got %s", c.a, c.b, got)
+		}
+	}
+	if _, err := (Duration{Y: math.MaxInt}).Add(Duration{Y: 1}); err != ErrOverflow {
+		t.Fatalf("want ErrOverflow, got %v", err)
+	}
+}
+
+func TestCanNeg(t *testing.T) {
+	d := Duration{D: 1}
+	if got := d.Neg(); got != (Duration{D: 1, Negative: true}) {
+		t.Fatalf("unexpected %+v", got)
+	}
+	if got := d.Neg().Neg(); got != d {
+		t.Fatalf("unexpected %+v", got)
+	}
+	if got := (Duration{}).Neg(); got != (Duration{}) {
+		t.Fatalf("unexpected %+v", got)
+	}
+}
+
+func TestCanScale(t *testing.T) {
+	cases := []struct {
+		d    string
+		n    int
+		want string
+	}{
+		{"P1Y2M3W4DT5H6M7S", 2, "P2Y4M6W8DT10H12M14S"},
+		{"P1D", -3, "-P3D"},
+		{"-P1D", -3, "P3D"},
+		{"P1D", 0, "P0D"},
+		{"PT0.5S", 3, "PT1.5S"},
+		{"P0.25D", 4, "P1D"},
+	}
+	for _, c := range cases {
+		d, err := ParseDuration(c.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := d.Scale(c.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != c.want {
+			t.Fatalf("%s * %d: want=%s, got=%s", c.d, c.n, c.want, got)
+		}
+	}
+
+	if _, err := (Duration{D: math.MaxInt/2 + 1}).Scale(2); err != ErrOverflow {
+		t.Fatalf("want ErrOverflow, got %v", err)
+	}
+	if _, err := (Duration{D: 1}).Scale(math.MinInt); err != ErrOverflow {
+		t.Fatalf("want ErrOverflow, got %v", err)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want string
+	}{
+		{"Jan 1, 2018 at 00:00:00", "Jan 1, 2018 at 00:00:00", "P0D"},
+		{"Jan 1, 2018 at 00:00:00", "Jun 9, 2028 at 05:10:06", "P10Y5M8DT5H10M6S"},
+		{"Jun 9, 2028 at 05:10:06", "Jan 1, 2018 at 00:00:00", "-P10Y5M8DT5H10M6S"},
+		{"Jan 31, 2022 at 00:00:00", "Mar 1, 2022 at 00:00:00", "P29D"},
+		{"Jan 31, 2022 at 00:00:00", "Mar 3, 2022 at 00:00:00", "P1M"},
+		{"Jan 31, 2022 at 00:00:00", "Mar 31, 2022 at 00:00:00", "P2M"},
+		{"Mar 31, 2022 at 00:00:00", "Feb 28, 2022 at 00:00:00", "-P1M3D"},
+		{"Jan 1, 2018 at 10:00:00", "Jan 2, 2018 at 09:00:00", "PT23H"},
+		{"Feb 29, 2020 at 00:00:00", "Feb 28, 2021 at 00:00:00", "P11M30D"},
+	}
+
+	for _, c := range cases {
+		a, b := makeTime(t, c.a), makeTime(t, c.b)
+		got := Between(a, b)
+		if got.String() != c.want {
+			t.Fatalf("Between(%s, %s): want=%s, got=%s", c.a, c.b, c.want, got)
+		}
+		if shifted := got.Shift(a); !shifted.Equal(b) {
+			t.Fatalf("Between(%s, %s) = %s shifts to %s", c.a, c.b, got, shifted)
+		}
+	}
+}
+
+func TestBetweenThroughDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := time.Date(2018, time.March, 10, 0, 0, 0, 0, loc)
+	b := time.Date(2018, time.March, 12, 0, 0, 0, 0, loc)
+	if got := Between(a, b); got != (Duration{D: 2}) {
+		t.Fatalf("want=P2D, got=%s", got)
+	}
+	// Same instant as b, but in UTC: it's b on a's wall clock all the same.
+	if got := Between(a, b.UTC()); got != (Duration{D: 2}) {
+		t.Fatalf("want=P2D, got=%s", got)
+	}
+
+	// Every pair of hours in a year around both DST transitions must round
+	// trip through Shift.
+	start := time.Date(2018, time.January, 1, 0, 30, 0, 0, loc)
+	for i := 0; i < 365*24; i += 7 {
+		a := start.Add(time.Duration(i) * time.Hour)
+		for _, j := range []int{1, 23, 24, 25, 49, 24 * 40, 24*365 + 5} {
+			for _, b := range []time.Time{a.Add(time.Duration(j) * time.Hour), a.Add(-time.Duration(j) * time.Hour)} {
+				d := Between(a, b)
+				if got := d.Shift(a); !got.Equal(b) {
+					t.Fatalf("Between(%s, %s) = %s shifts to %s", a, b, d, got)
+				}
+			}
+		}
+	}
+}
+
+func TestTimeDuration(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstStart := time.Date(2018, time.March, 11, 0, 0, 0, 0, loc)
+
+	cases := []struct {
+		d    string
+		ref  time.Time
+		want time.Duration
+	}{
+		{"PT1H30M", time.Time{}, 90 * time.Minute},
+		{"-PT1.5S", time.Time{}, -1500 * time.Millisecond},
+		{"P1DT1H", makeTime(t, "Jan 1, 2018 at 00:00:00"), 25 * time.Hour},
+		{"P1D", dstStart, 23 * time.Hour},
+		{"-P1D", dstStart.AddDate(0, 0, 1), -23 * time.Hour},
+		{"P1M", makeTime(t, "Feb 1, 2018 at 00:00:00"), 28 * 24 * time.Hour},
+		{"P0.5D", makeTime(t, "Jan 1, 2018 at 00:00:00"), 12 * time.Hour},
+	}
+	for _, c := range cases {
+		d, err := ParseDuration(c.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := d.TimeDuration(c.ref)
+		if err != nil {
+			t.Fatalf("%s: %v", c.d, err)
+		}
+		if got != c.want {
+			t.Fatalf("%s: want=%s, got=%s", c.d, c.want, got)
+		}
+	}
+}
+
+func TestTimeDurationErrors(t *testing.T) {
+	ref := makeTime(t, "Jan 1, 2018 at 00:00:00")
+	cases := []struct {
+		d    Duration
+		ref  time.Time
+		want error
+	}{
+		{Duration{D: 1, TH: 1}, time.Time{}, ErrAmbiguous},
+		{Duration{Frac: 500000000, FracUnit: Weeks}, time.Time{}, ErrAmbiguous},
+		{Duration{TH: math.MaxInt/int(time.Hour) + 1}, time.Time{}, ErrOverflow},
+		{Duration{TH: 2562047, TM: 48}, time.Time{}, ErrOverflow},
+		{Duration{Y: 300}, ref, ErrOverflow},
+		{Duration{D: 1, TH: math.MaxInt/int(time.Hour) + 1}, ref, ErrOverflow},
+	}
+	for _, c := range cases {
+		if _, err := c.d.TimeDuration(c.ref); err != c.want {
+			t.Fatalf("%+v: want %v, got %v", c.d, c.want, err)
+		}
+	}
+}
+
+func TestFromTimeDuration(t *testing.T) {
+	cases := []struct {
+		td      time.Duration
+		largest Unit
+		want    string
+	}{
+		{0, Hours, "P0D"},
+		{26*time.Hour + 3*time.Minute + 4*time.Second, Hours, "PT26H3M4S"},
+		{26*time.Hour + 3*time.Minute + 4*time.Second, Days, "P1DT2H3M4S"},
+		{15 * 24 * time.Hour, Weeks, "P2W1D"},
+		{15 * 24 * time.Hour, Days, "P15D"},
+		{90 * time.Minute, Minutes, "PT90M"},
+		{90 * time.Minute, Seconds, "PT5400S"},
+		{-1500 * time.Millisecond, Hours, "-PT1.5S"},
+		{time.Nanosecond, Hours, "PT0.000000001S"},
+		{math.MinInt64, Hours, "-PT2562047H47M16.854775808S"},
+	}
+	for _, c := range cases {
+		got := FromTimeDuration(c.td, c.largest)
+		if got.String() != c.want {
+			t.Fatalf("%s: want=%s, got=%s", c.td, c.want, got)
+		}
+		if back, err := got.TimeDuration(makeTime(t, "Jan 1, 2018 at 00:00:00")); err != nil || back != c.td {
+			t.Fatalf("%s: round trip gave %s, %v", c.td, back, err)
+		}
+	}
+}
+
+func TestShiftWithMonthEnd(t *testing.T) {
+	cases := []struct {
+		from     string
+		duration string
+		policy   MonthEndPolicy
+		want     string
+	}{
+		{"Aug 31, 2018 at 00:00:00", "P1M", MonthEndRollOver, "Oct 1, 2018 at 00:00:00"},
+		{"Aug 31, 2018 at 00:00:00", "P1M", MonthEndClamp, "Sep 30, 2018 at 00:00:00"},
+		{"Aug 31, 2018 at 00:00:00", "P1M1D", MonthEndClamp, "Oct 1, 2018 at 00:00:00"},
+		{"Jan 31, 2018 at 06:30:00", "P1MT1H", MonthEndClamp, "Feb 28, 2018 at 07:30:00"},
+		{"Feb 29, 2020 at 00:00:00", "P1Y", MonthEndClamp, "Feb 28, 2021 at 00:00:00"},
+		{"Mar 31, 2018 at 00:00:00", "-P1M", MonthEndClamp, "Feb 28, 2018 at 00:00:00"},
+		{"Jan 31, 2018 at 00:00:00", "P14M", MonthEndClamp, "Mar 31, 2019 at 00:00:00"},
+		{"Jan 31, 2018 at 00:00:00", "P1D", MonthEndError, "Feb 1, 2018 at 00:00:00"},
+		{"Jan 30, 2018 at 00:00:00", "P2M", MonthEndError, "Mar 30, 2018 at 00:00:00"},
+	}
+	for k, c := range cases {
+		d, err := ParseDuration(c.duration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := d.ShiftWith(makeTime(t, c.from), ShiftOptions{MonthEnd: c.policy})
+		if err != nil {
+			t.Fatalf("Case %d: %v", k, err)
+		}
+		if want := makeTime(t, c.want); !want.Equal(got) {
+			t.Fatalf("Case %d: want=%s, got=%s", k, want, got)
+		}
+	}
+
+	for _, c := range []string{"P1M", "P1Y1M", "-P1M", "P0.5M"} {
+		d, err := ParseDuration(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = d.ShiftWith(makeTime(t, "Mar 31, 2018 at 00:00:00"), ShiftOptions{MonthEnd: MonthEndError})
+		if !errors.Is(err, ErrMonthEnd) {
+			t.Fatalf("%s: want ErrMonthEnd, got %v", c, err)
+		}
+	}
+}
+
+func TestShiftWithDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2018, time.March, 10, 12, 0, 0, 0, loc)
+
+	cases := []struct {
+		duration string
+		policy   DSTPolicy
+		want     time.Time
+	}{
+		{"P1D", DSTWallClock, time.Date(2018, time.March, 11, 12, 0, 0, 0, loc)},
+		{"P1D", DSTElapsed, time.Date(2018, time.March, 11, 13, 0, 0, 0, loc)},
+		{"P1W", DSTElapsed, time.Date(2018, time.March, 17, 13, 0, 0, 0, loc)},
+		{"PT24H", DSTWallClock, time.Date(2018, time.March, 11, 13, 0, 0, 0, loc)},
+		{"-P1D", DSTElapsed, time.Date(2018, time.March, 9, 12, 0, 0, 0, loc)},
+	}
+	for _, c := range cases {
+		d, err := ParseDuration(c.duration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := d.ShiftWith(from, ShiftOptions{DST: c.policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !c.want.Equal(got) {
+			t.Fatalf("%s with %d: want=%s, got=%s", c.duration, c.policy, c.want, got)
+		}
+		if got.Location() != loc {
+			t.Fatalf("%s with %d: location changed to %s", c.duration, c.policy, got.Location())
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		d     string
+		weeks bool
+		want  string
+	}{
+		{"PT90M", false, "PT1H30M"},
+		{"PT1H30M", false, "PT1H30M"},
+		{"PT3661S", false, "PT1H1M1S"},
+		{"PT48H", false, "PT48H"},
+		{"P14M", false, "P1Y2M"},
+		{"P15D", false, "P15D"},
+		{"P15D", true, "P2W1D"},
+		{"P1W8D", true, "P2W1D"},
+		{"PT1.5H", false, "PT1H30M"},
+		{"PT0.51M", false, "PT30.6S"},
+		{"PT90.5S", false, "PT1M30.5S"},
+		{"P8.5D", true, "P1W1.5D"},
+		{"-PT90M", false, "-PT1H30M"},
+		{"P0D", true, "P0D"},
+	}
+	for _, c := range cases {
+		d, err := ParseDuration(c.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := d.Normalize(c.weeks)
+		if got.String() != c.want {
+			t.Fatalf("%s: want=%s, got=%s", c.d, c.want, got)
+		}
+		from := makeTime(t, "Jan 31, 2018 at 00:00:00")
+		if !got.Shift(from).Equal(d.Shift(from)) {
+			t.Fatalf("%s: normalized %s shifts differently", c.d, got)
+		}
+	}
+
+	a, _ := ParseDuration("PT90M")
+	b, _ := ParseDuration("PT1H30M")
+	if a.Normalize(false) != b.Normalize(false) {
+		t.Fatalf("%s and %s must normalize to the same duration", a, b)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		anchor string
+		want   int
+	}{
+		{"PT90M", "PT1H30M", "Jan 1, 2018 at 00:00:00", 0},
+		{"PT1H", "PT59M", "Jan 1, 2018 at 00:00:00", 1},
+		{"P1D", "PT25H", "Jan 1, 2018 at 00:00:00", -1},
+		{"P1M", "P30D", "Feb 1, 2018 at 00:00:00", -1},
+		{"P1M", "P30D", "Mar 1, 2018 at 00:00:00", 1},
+		{"P1M", "P31D", "Mar 1, 2018 at 00:00:00", 0},
+		{"-P1D", "P0D", "Jan 1, 2018 at 00:00:00", -1},
+		{"P1Y", "P12M", "Feb 29, 2020 at 00:00:00", 0},
+	}
+	for _, c := range cases {
+		a, err := ParseDuration(c.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ParseDuration(c.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		anchor := makeTime(t, c.anchor)
+		if got := a.Compare(b, anchor); got != c.want {
+			t.Fatalf("%s vs %s from %s: want=%d, got=%d", c.a, c.b, c.anchor, c.want, got)
+		}
+		if got := b.Compare(a, anchor); got != -c.want {
+			t.Fatalf("%s vs %s from %s: want=%d, got=%d", c.b, c.a, c.anchor, -c.want, got)
+		}
+	}
+}
+
 func TestCanMaintainHourThroughDST(t *testing.T) {
 	loc, err := time.LoadLocation("America/New_York")
 	if err != nil {
@@ -89,6 +618,23 @@ func TestCanParse(t *testing.T) {
 		{"PT1M", Duration{TM: 1}},
 		{"PT1S", Duration{TS: 1}},
 		{"P10Y5M8DT5H10M6S", Duration{Y: 10, M: 5, D: 8, TH: 5, TM: 10, TS: 6}},
+		{"PT1.5S", Duration{TS: 1, Frac: 500000000, FracUnit: Seconds}},
+		{"PT0,25S", Duration{Frac: 250000000, FracUnit: Seconds}},
+		{"P0.5D", Duration{Frac: 500000000, FracUnit: Days}},
+		{"P1DT2.000000001H", Duration{D: 1, TH: 2, Frac: 1, FracUnit: Hours}},
+		{"PT1.0S", Duration{TS: 1}},
+		{"-P1D", Duration{D: 1, Negative: true}},
+		{"-PT30M", Duration{TM: 30, Negative: true}},
+		{"+P1D", Duration{D: 1}},
+		{"-P0D", Duration{}},
+		{"-PT0.0S", Duration{}},
+		{"-P0000-00-00", Duration{}},
+		{"P0003-06-04T12:30:05", Duration{Y: 3, M: 6, D: 4, TH: 12, TM: 30, TS: 5}},
+		{"P00030604T123005", Duration{Y: 3, M: 6, D: 4, TH: 12, TM: 30, TS: 5}},
+		{"P0000-00-01", Duration{D: 1}},
+		{"P00000001", Duration{D: 1}},
+		{"-P0000-00-00T00:00:01,5", Duration{TS: 1, Frac: 500000000, FracUnit: Seconds, Negative: true}},
+		{"P0000-12-30T24:59:59", Duration{M: 12, D: 30, TH: 24, TM: 59, TS: 59}},
 	}
 
 	for k, c := range cases {
@@ -108,6 +654,22 @@ func TestCanRejectBadString(t *testing.T) {
 		"PP1D",
 		"P1D2F",
 		"P2F",
+		"P1.5DT1H",
+		"PT1.5M2S",
+		"PT1.S",
+		"PT.5S",
+		"PT0.0000000001S",
+		"--P1D",
+		"P-1D",
+		"-",
+		"P0003-13-04T12:30:05",
+		"P0003-06-31T12:30:05",
+		"P0003-06-04T25:30:05",
+		"P0003-06-04T12:60:05",
+		"P0003-06-04T12:30:60",
+		"P00031304T123005",
+		"P0003-06-04T123005",
+		"P3-06-04",
 	}
 
 	for _, c := range cases {
@@ -119,11 +681,15 @@ func TestCanRejectBadString(t *testing.T) {
 }
 
 func TestCanStringifyZeroValue(t *testing.T) {
-	sut := Duration{}
-	want := "P0D"
-	got := sut.String()
-	if want != got {
-		t.Fatalf("want=%s, got=%s", want, got)
+	for _, sut := range []Duration{{}, {Negative: true}} {
+		if !sut.IsZero() {
+			t.Fatalf("%+v: want IsZero", sut)
+		}
+		want := "P0D"
+		got := sut.String()
+		if want != got {
+			t.Fatalf("want=%s, got=%s", want, got)
+		}
 	}
 }
 
@@ -137,6 +703,12 @@ func TestCanStringify(t *testing.T) {
 		"PT6M",
 		"PT7S",
 		"P1Y2M3W4DT5H6M7S",
+		"PT0.25S",
+		"P0.5D",
+		"P1Y1.000000001M",
+		"PT1H0.5M",
+		"-P1D",
+		"-P1Y2M3W4DT5H6M7.5S",
 	}
 	for _, want := range cases {
 		sut, err := ParseDuration(want)
@@ -150,6 +722,71 @@ func TestCanStringify(t *testing.T) {
 	}
 }
 
+func TestCanFormatAlternative(t *testing.T) {
+	cases := []struct {
+		d        Duration
+		extended string
+		basic    string
+	}{
+		{Duration{}, "P0000-00-00T00:00:00", "P00000000T000000"},
+		{Duration{Y: 3, M: 6, D: 4, TH: 12, TM: 30, TS: 5}, "P0003-06-04T12:30:05", "P00030604T123005"},
+		{Duration{W: 2, D: 1}, "P0000-00-15T00:00:00", "P00000015T000000"},
+		{Duration{TS: 5, Frac: 250000000, FracUnit: Seconds, Negative: true}, "-P0000-00-00T00:00:05.25", "-P00000000T000005.25"},
+	}
+	for _, c := range cases {
+		for _, n := range []struct {
+			notation Notation
+			want     string
+		}{{ExtendedNotation, c.extended}, {BasicNotation, c.basic}} {
+			got, err := FormatDuration(c.d, n.notation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != n.want {
+				t.Fatalf("want=%s, got=%s", n.want, got)
+			}
+			back, err := ParseDuration(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustFormat(t, back, n.notation); want != got {
+				t.Fatalf("round trip: want=%s, got=%s", got, want)
+			}
+		}
+	}
+
+	if got, _ := FormatDuration(Duration{D: 1}, DesignatorNotation); got != "P1D" {
+		t.Fatalf("want=P1D, got=%s", got)
+	}
+}
+
+func mustFormat(t *testing.T, d Duration, n Notation) string {
+	t.Helper()
+	s, err := FormatDuration(d, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCanRejectAlternative(t *testing.T) {
+	cases := []Duration{
+		{Y: 10000},
+		{M: 13},
+		{D: 31},
+		{W: 4, D: 3},
+		{TH: 25},
+		{TM: 60},
+		{TS: 60},
+		{D: 1, Frac: 500000000, FracUnit: Days},
+	}
+	for _, c := range cases {
+		if s, err := FormatDuration(c, ExtendedNotation); err == nil {
+			t.Fatalf("%+v: expected error, got %s", c, s)
+		}
+	}
+}
+
 func TestCanMarshalJSON(t *testing.T) {
 	s := "P1Y2M3W4DT5H6M7S"
 	sut, _ := ParseDuration(s)
@@ -182,6 +819,42 @@ func TestCanUnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestCanRoundTripFractionInJSON(t *testing.T) {
+	want := Duration{TH: 1, Frac: 123456789, FracUnit: Hours}
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `"PT1.123456789H"` {
+		t.Fatalf("unexpected JSON: %s", b)
+	}
+	var got Duration
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("want=%+v, got=%+v", want, got)
+	}
+}
+
+func TestCanRoundTripNegativeInJSON(t *testing.T) {
+	want := Duration{D: 1, Negative: true}
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `"-P1D"` {
+		t.Fatalf("unexpected JSON: %s", b)
+	}
+	var got Duration
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Fatalf("want=%+v, got=%+v", want, got)
+	}
+}
+
 func TestCanRejectDurationInJSON(t *testing.T) {
 	j := []byte(`"PZY"`)
 	var got Duration
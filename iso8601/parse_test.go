@@ -0,0 +1,319 @@
+package iso8601
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The regexp and template based implementation that ParseDuration and
+// String replaced, kept as a reference for FuzzParseDuration.
+
+var refPattern = regexp.MustCompile(`^(?P<sign>[-+])?P((?P<year>` + refNum + `)Y)?((?P<month>` + refNum + `)M)?((?P<week>` + refNum + `)W)?((?P<day>` + refNum + `)D)?(T((?P<hour>` + refNum + `)H)?((?P<minute>` + refNum + `)M)?((?P<second>` + refNum + `)S)?)?$`)
+
+const refNum = `\d+(?:[.,]\d+)?`
+
+var refUnits = map[string]Unit{
+	"year":   Years,
+	"month":  Months,
+	"week":   Weeks,
+	"day":    Days,
+	"hour":   Hours,
+	"minute": Minutes,
+	"second": Seconds,
+}
+
+var (
+	refAltExtendedPattern = regexp.MustCompile(`^([-+])?P(\d{4})-(\d{2})-(\d{2})(?:T(\d{2}):(\d{2}):(\d{2}(?:[.,]\d+)?))?$`)
+	refAltBasicPattern    = regexp.MustCompile(`^([-+])?P(\d{4})(\d{2})(\d{2})(?:T(\d{2})(\d{2})(\d{2}(?:[.,]\d+)?))?$`)
+)
+
+func refParseDuration(from string) (Duration, error) {
+	var d Duration
+
+	var match []string
+	if refPattern.MatchString(from) {
+		match = refPattern.FindStringSubmatch(from)
+	} else if refAltExtendedPattern.MatchString(from) {
+		return refParseAlternative(refAltExtendedPattern.FindStringSubmatch(from))
+	} else if refAltBasicPattern.MatchString(from) {
+		return refParseAlternative(refAltBasicPattern.FindStringSubmatch(from))
+	} else {
+		return d, errors.New("could not parse duration string")
+	}
+
+	// Unlike the regexp, ParseDuration rejects empty durations and empty
+	// time parts.
+	if i := strings.IndexByte(from, 'T'); i == len(from)-1 || from[len(from)-1] == 'P' {
+		return d, errors.New("empty duration or time part")
+	}
+
+	var fracSeen bool
+	for i, name := range refPattern.SubexpNames() {
+		part := match[i]
+		if i == 0 || name == "" || part == "" {
+			continue
+		}
+		if name == "sign" {
+			d.Negative = part == "-"
+			continue
+		}
+		if fracSeen {
+			return d, errors.New("only the lowest order component can have a fraction")
+		}
+		intPart, fracPart, hasFrac := cutFrac(part)
+		if hasFrac {
+			fracSeen = true
+			frac, err := parseFrac(fracPart)
+			if err != nil {
+				return d, err
+			}
+			if frac != 0 {
+				d.Frac = frac
+				d.FracUnit = refUnits[name]
+			}
+		}
+		val, err := strconv.Atoi(intPart)
+		if err != nil {
+			return d, err
+		}
+		*d.field(refUnits[name]) = val
+	}
+	d.Negative = d.Negative && !d.IsZero()
+	return d, nil
+}
+
+func refParseAlternative(match []string) (Duration, error) {
+	var d Duration
+	d.Negative = match[1] == "-"
+
+	var vals [len(altLimits)]int
+	for i, part := range match[2:] {
+		if part == "" {
+			continue
+		}
+		intPart, fracPart, hasFrac := cutFrac(part)
+		if hasFrac {
+			frac, err := parseFrac(fracPart)
+			if err != nil {
+				return d, err
+			}
+			if frac != 0 {
+				d.Frac = frac
+				d.FracUnit = Seconds
+			}
+		}
+		val, err := strconv.Atoi(intPart)
+		if err != nil {
+			return d, err
+		}
+		if val > altLimits[i].max {
+			return d, errors.New("out of range")
+		}
+		vals[i] = val
+	}
+	d.Y, d.M, d.D, d.TH, d.TM, d.TS = vals[0], vals[1], vals[2], vals[3], vals[4], vals[5]
+	d.Negative = d.Negative && !d.IsZero()
+	return d, nil
+}
+
+func refComponent(d Duration, u Unit) string {
+	n := *d.field(u)
+	if d.Frac == 0 || d.FracUnit != u {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n) + string(designators[u-1])
+	}
+	return strconv.Itoa(n) + "." + fracString(d.Frac) + string(designators[u-1])
+}
+
+var refTmpl = template.Must(template.New("duration").Funcs(template.FuncMap{
+	"component": refComponent,
+}).Parse(`{{if .Negative}}-{{end}}P{{component . 1}}{{component . 2}}{{component . 3}}{{component . 4}}{{if .HasTimePart}}T{{end }}{{component . 5}}{{component . 6}}{{component . 7}}`))
+
+func refString(d Duration) string {
+	if d.IsZero() {
+		return "P0D"
+	}
+	var s bytes.Buffer
+	if err := refTmpl.Execute(&s, d); err != nil {
+		panic(err)
+	}
+	return s.String()
+}
+
+var parseSamples = []string{
+	"P1Y",
+	"PT1S",
+	"P10Y5M8DT5H10M6S",
+	"P1Y2M3W4DT5H6M7S",
+	"-PT0,25S",
+	"P0.5D",
+	"P0003-06-04T12:30:05",
+	"P00030604T123005.5",
+	"P",
+	"PT",
+	"P1DT",
+	"PP1D",
+	"P1D2F",
+	"P1.5DT1H",
+	"PT1.S",
+	"P99999999999999999999D",
+	"PT1.0000000001S",
+}
+
+func FuzzParseDuration(f *testing.F) {
+	for _, s := range parseSamples {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		got, err := ParseDuration(s)
+		want, wantErr := refParseDuration(s)
+		if (err == nil) != (wantErr == nil) {
+			t.Fatalf("%q: got error %v, reference error %v", s, err, wantErr)
+		}
+		if err != nil {
+			return
+		}
+		if got != want {
+			t.Fatalf("%q: got %+v, reference %+v", s, got, want)
+		}
+
+		str := got.String()
+		if ref := refString(got); str != ref {
+			t.Fatalf("%q: String() = %q, reference %q", s, str, ref)
+		}
+		back, err := ParseDuration(str)
+		if err != nil {
+			t.Fatalf("%q: can't parse back %q: %v", s, str, err)
+		}
+		if back != got {
+			t.Fatalf("%q: round trip through %q gave %+v, want %+v", s, str, back, got)
+		}
+	})
+}
+
+func TestParseDurationDoesNotAllocate(t *testing.T) {
+	for _, s := range []string{"P1Y2M3W4DT5H6M7S", "-PT0,25S", "P0003-06-04T12:30:05.5"} {
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := ParseDuration(s); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if allocs != 0 {
+			t.Fatalf("%s: got %v allocations, want 0", s, allocs)
+		}
+	}
+}
+
+func BenchmarkParseDuration(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseDuration("P1Y2M3W4DT5H6M7.5S"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseDurationReference(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := refParseDuration("P1Y2M3W4DT5H6M7.5S"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	d := Duration{Y: 1, M: 2, W: 3, D: 4, TH: 5, TM: 6, TS: 7, Frac: 500000000, FracUnit: Seconds}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = d.String()
+	}
+}
+
+func BenchmarkStringReference(b *testing.B) {
+	d := Duration{Y: 1, M: 2, W: 3, D: 4, TH: 5, TM: 6, TS: 7, Frac: 500000000, FracUnit: Seconds}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = refString(d)
+	}
+}
+
+func TestParseDurationError(t *testing.T) {
+	cases := []struct {
+		from       string
+		wantOffset int
+		wantReason string
+	}{
+		{"", 0, "unexpected end"},
+		{"1D", 0, "unexpected character"},
+		{"P", 1, "empty duration"},
+		{"-P", 2, "empty duration"},
+		{"PT", 1, "empty time part"},
+		{"P1DT", 3, "empty time part"},
+		{"P1D2F", 4, "unexpected character"},
+		{"P1DT5", 5, "unexpected end"},
+		{"P1D1Y", 4, "out-of-order designator"},
+		{"P1D1D", 4, "out-of-order designator"},
+		{"PT1M1H", 5, "out-of-order designator"},
+		{"PT1HT1M", 4, "unexpected character"},
+		{"P1.5DT1H", 6, "component after a fraction"},
+		{"PT1.S", 4, "unexpected character"},
+		{"P99999999999999999999D", 1, "overflow"},
+		{"PT1.0000000001S", 4, "fraction has more than 9 significant digits"},
+		{"P0003-13-04", 6, "month out of range"},
+		{"P0003-06-04T12:30", 17, "unexpected end"},
+		{"p1d", 0, "unexpected character"},
+		{" P1D", 0, "unexpected character"},
+	}
+	for _, c := range cases {
+		_, err := ParseDuration(c.from)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("%q: want *ParseError, got %v", c.from, err)
+		}
+		if perr.Input != c.from || perr.Offset != c.wantOffset || perr.Reason != c.wantReason {
+			t.Fatalf("%q: want offset %d and reason %q, got %d and %q", c.from, c.wantOffset, c.wantReason, perr.Offset, perr.Reason)
+		}
+	}
+
+	if _, err := ParseDuration("P99999999999999999999D"); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("want ErrOverflow, got %v", err)
+	}
+}
+
+func TestCanParseDurationLeniently(t *testing.T) {
+	cases := []struct {
+		from string
+		want Duration
+	}{
+		{"P", Duration{}},
+		{"PT", Duration{}},
+		{"-pt", Duration{}},
+		{"  P1D\n", Duration{D: 1}},
+		{"p1y2m3dt4h5m6.5s", Duration{Y: 1, M: 2, D: 3, TH: 4, TM: 5, TS: 6, Frac: 500000000, FracUnit: Seconds}},
+		{"p0003-06-04t12:30:05", Duration{Y: 3, M: 6, D: 4, TH: 12, TM: 30, TS: 5}},
+		{"P00030604t123005", Duration{Y: 3, M: 6, D: 4, TH: 12, TM: 30, TS: 5}},
+	}
+	for _, c := range cases {
+		got, err := ParseDurationLenient(c.from)
+		if err != nil {
+			t.Fatalf("%q: %v", c.from, err)
+		}
+		if got != c.want {
+			t.Fatalf("%q: want=%+v, got=%+v", c.from, c.want, got)
+		}
+	}
+
+	for _, s := range []string{"", " ", "P1DT", "P 1D", "x"} {
+		if _, err := ParseDurationLenient(s); err == nil {
+			t.Fatalf("%q: expected error, got none", s)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package iso8601
+
+import (
+	"errors"
+	"time"
+)
+
+// Period is the span of time denoted by a date or time of reduced
+// precision: 2022 is the whole year, 2022-05 the month of May, 2022-W05 the
+// fifth ISO week, 2022-05-17 a day and 2022-05-17T10 an hour.
+//
+// A period includes its start and excludes its end.
+type Period struct {
+	start time.Time
+	prec  Unit
+}
+
+// ParsePeriod parses a date or time with ParseTime and returns the period of
+// its precision that starts at it. Fractions, such as in 2022-05-17T10.5,
+// aren't allowed.
+func ParsePeriod(s string) (Period, error) {
+	return ParsePeriodInLocation(s, time.UTC)
+}
+
+// ParsePeriodInLocation is like ParsePeriod, but interprets times without a
+// time zone designator in the given location.
+func ParsePeriodInLocation(s string, loc *time.Location) (Period, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '.' || s[i] == ',' {
+			return Period{}, errors.New("period can't have a fraction")
+		}
+	}
+	t, prec, err := ParseTimeInLocation(s, loc)
+	if err != nil {
+		return Period{}, err
+	}
+	return Period{start: t, prec: prec}, nil
+}
+
+// NewPeriod returns the period of the given unit that contains t, in the
+// location of t. Weeks are ISO weeks, which start on Monday.
+func NewPeriod(t time.Time, u Unit) Period {
+	var d Duration
+	*d.field(u) = 1
+	// A single unit can always be aligned to.
+	start, _ := d.Truncate(t, AlignOptions{WeekStart: time.Monday})
+	return Period{start: start, prec: u}
+}
+
+// Start returns the start of the period.
+func (p Period) Start() time.Time { return p.start }
+
+// End returns the end of the period, which is also the start of the next
+// one.
+func (p Period) End() time.Time { return p.Duration().Shift(p.start) }
+
+// Precision returns the unit the period is one of.
+func (p Period) Precision() Unit { return p.prec }
+
+// Duration returns the length of the period, such as P1M for 2022-05.
+func (p Period) Duration() Duration {
+	var d Duration
+	if p.prec != 0 {
+		*d.field(p.prec) = 1
+	}
+	return d
+}
+
+// Interval returns the period as an interval from its start.
+func (p Period) Interval() Interval { return IntervalFrom(p.start, p.Duration()) }
+
+// Contains reports whether t falls within the period.
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.start) && t.Before(p.End())
+}
+
+// Next returns the period that follows p.
+func (p Period) Next() Period { return Period{start: p.End(), prec: p.prec} }
+
+// Prev returns the period that precedes p.
+func (p Period) Prev() Period {
+	back := p.Duration()
+	back.Negative = true
+	return Period{start: back.Shift(p.start), prec: p.prec}
+}
+
+// String returns the ISO 8601 representation of the period, such as 2022-05
+// or 2022-W05.
+func (p Period) String() string {
+	return FormatTime(p.start, TimeLayout{Precision: p.prec})
+}
@@ -0,0 +1,134 @@
+package iso8601
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanParsePeriod(t *testing.T) {
+	cases := []struct {
+		from      string
+		wantStart string
+		wantEnd   string
+		wantDur   string
+		wantPrev  string
+		wantNext  string
+	}{
+		{"2022", "2022-01-01T00:00:00Z", "2023-01-01T00:00:00Z", "P1Y", "2021", "2023"},
+		{"2022-05", "2022-05-01T00:00:00Z", "2022-06-01T00:00:00Z", "P1M", "2022-04", "2022-06"},
+		{"2022-01", "2022-01-01T00:00:00Z", "2022-02-01T00:00:00Z", "P1M", "2021-12", "2022-02"},
+		{"2022-W05", "2022-01-31T00:00:00Z", "2022-02-07T00:00:00Z", "P1W", "2022-W04", "2022-W06"},
+		{"2020-W53", "2020-12-28T00:00:00Z", "2021-01-04T00:00:00Z", "P1W", "2020-W52", "2021-W01"},
+		{"2022-02-28", "2022-02-28T00:00:00Z", "2022-03-01T00:00:00Z", "P1D", "2022-02-27", "2022-03-01"},
+		{"2022-05-17T23Z", "2022-05-17T23:00:00Z", "2022-05-18T00:00:00Z", "PT1H", "2022-05-17T22Z", "2022-05-18T00Z"},
+		{"2022-05-17T10:15+03:00", "2022-05-17T10:15:00+03:00", "2022-05-17T10:16:00+03:00", "PT1M", "2022-05-17T10:14+03:00", "2022-05-17T10:16+03:00"},
+	}
+	for _, c := range cases {
+		p, err := ParsePeriod(c.from)
+		if err != nil {
+			t.Fatalf("%s: %v", c.from, err)
+		}
+		if want := mustParseRFC3339(t, c.wantStart); !want.Equal(p.Start()) {
+			t.Fatalf("%s: start: want=%s, got=%s", c.from, want, p.Start())
+		}
+		if want := mustParseRFC3339(t, c.wantEnd); !want.Equal(p.End()) {
+			t.Fatalf("%s: end: want=%s, got=%s", c.from, want, p.End())
+		}
+		if got := p.Duration().String(); got != c.wantDur {
+			t.Fatalf("%s: duration: want=%s, got=%s", c.from, c.wantDur, got)
+		}
+		if got := p.String(); got != c.from {
+			t.Fatalf("want=%s, got=%s", c.from, got)
+		}
+		if got := p.Prev().String(); got != c.wantPrev {
+			t.Fatalf("%s: previous: want=%s, got=%s", c.from, c.wantPrev, got)
+		}
+		if got := p.Next().String(); got != c.wantNext {
+			t.Fatalf("%s: next: want=%s, got=%s", c.from, c.wantNext, got)
+		}
+	}
+
+	for _, s := range []string{"", "2022-13", "2022-05-17T10.5", "2022-05-17T10,5Z"} {
+		if _, err := ParsePeriod(s); err == nil {
+			t.Fatalf("%q: expected error, got none", s)
+		}
+	}
+}
+
+func TestPeriodContains(t *testing.T) {
+	p, err := ParsePeriod("2022-05")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		t    string
+		want bool
+	}{
+		{"2022-04-30T23:59:59Z", false},
+		{"2022-05-01T00:00:00Z", true},
+		{"2022-05-31T23:59:59.999999999Z", true},
+		{"2022-06-01T00:00:00Z", false},
+		{"2022-06-01T02:00:00+03:00", true},
+	}
+	for _, c := range cases {
+		if got := p.Contains(mustParseRFC3339(t, c.t)); got != c.want {
+			t.Fatalf("%s: want=%v, got=%v", c.t, c.want, got)
+		}
+	}
+}
+
+func TestNewPeriod(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm := time.Date(2022, time.May, 17, 22, 30, 0, 0, loc)
+	cases := []struct {
+		u    Unit
+		want string
+	}{
+		{Years, "2022"},
+		{Months, "2022-05"},
+		{Weeks, "2022-W20"},
+		{Days, "2022-05-17"},
+		{Hours, "2022-05-17T22-04:00"},
+		{Minutes, "2022-05-17T22:30-04:00"},
+	}
+	for _, c := range cases {
+		p := NewPeriod(tm, c.u)
+		if got := p.String(); got != c.want {
+			t.Fatalf("%s: want=%s, got=%s", c.u, c.want, got)
+		}
+		if !p.Contains(tm) {
+			t.Fatalf("%s: %s must contain %s", c.u, p, tm)
+		}
+		back, err := ParsePeriodInLocation(p.String(), loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !back.Start().Equal(p.Start()) {
+			t.Fatalf("%s: want=%s, got=%s", c.u, p.Start(), back.Start())
+		}
+	}
+}
+
+func TestNewPeriodInRepeatedHour(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 01:30 EST, an hour after 01:30 EDT on the day clocks went back.
+	tm := mustParseRFC3339(t, "2022-11-06T01:30:00-05:00").In(loc)
+	for _, u := range []Unit{Days, Hours, Minutes, Seconds} {
+		if p := NewPeriod(tm, u); !p.Contains(tm) {
+			t.Fatalf("%s: %s (%s/%s) must contain %s", u, p, p.Start(), p.End(), tm)
+		}
+	}
+	p := NewPeriod(tm, Hours)
+	if want := mustParseRFC3339(t, "2022-11-06T01:00:00-05:00"); !want.Equal(p.Start()) {
+		t.Fatalf("want=%s, got=%s", want, p.Start())
+	}
+	if want := mustParseRFC3339(t, "2022-11-06T01:00:00-04:00"); !want.Equal(p.Prev().Start()) {
+		t.Fatalf("want=%s, got=%s", want, p.Prev().Start())
+	}
+}
@@ -0,0 +1,133 @@
+package iso8601
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Repeating represents an ISO 8601 repeating interval, such as
+// R5/2022-01-01T00:00:00Z/P1W or R/2022-01-01T00:00:00Z/P1W. See
+// https://en.wikipedia.org/wiki/ISO_8601#Repeating_intervals.
+//
+// Occurrences are produced by repeatedly applying Duration.Shift. An interval
+// with a start repeats forwards from it; an interval given as
+// <duration>/<end> repeats backwards, so that its last occurrence ends at the
+// end.
+type Repeating struct {
+	// Repetitions is the number of occurrences, or -1 if the interval repeats
+	// indefinitely.
+	Repetitions int
+	Interval    Interval
+}
+
+// ParseRepeating parses an ISO 8601 repeating interval string. The interval
+// must be anchored in time, that is, it can't be just a duration.
+func ParseRepeating(s string) (Repeating, error) {
+	var r Repeating
+	head, rest, found := strings.Cut(s, "/")
+	if !found || !strings.HasPrefix(head, "R") {
+		return r, errors.New("could not parse repeating interval string")
+	}
+
+	r.Repetitions = -1
+	if n := head[1:]; n != "" {
+		var err error
+		r.Repetitions, err = strconv.Atoi(n)
+		if err != nil || r.Repetitions < 0 || n[0] == '+' {
+			return r, errors.New("invalid number of repetitions")
+		}
+	}
+
+	var err error
+	r.Interval, err = ParseInterval(rest)
+	if err != nil {
+		return r, err
+	}
+	if r.Interval.form == durationOnly {
+		return r, errors.New("repeating interval must have a start or an end")
+	}
+	if r.Interval.Duration().IsZero() {
+		return r, errors.New("repeating interval must have a non-zero duration")
+	}
+	return r, nil
+}
+
+// String returns an ISO 8601 representation of the repeating interval.
+func (r Repeating) String() string {
+	if r.Repetitions < 0 {
+		return "R/" + r.Interval.String()
+	}
+	return "R" + strconv.Itoa(r.Repetitions) + "/" + r.Interval.String()
+}
+
+// Next returns the start of the first occurrence that begins after the given
+// time. It returns false if there is no such occurrence.
+func (r Repeating) Next(after time.Time) (time.Time, bool) {
+	var next time.Time
+	var found bool
+	it := r.Iter()
+	for {
+		occ, ok := it.Next()
+		if !ok {
+			break
+		}
+		start := occ.Start()
+		if it.backwards {
+			if !start.After(after) {
+				break
+			}
+			next, found = start, true
+			continue
+		}
+		if start.After(after) {
+			return start, true
+		}
+	}
+	return next, found
+}
+
+// Iter returns an iterator over the occurrences of r. The occurrences are
+// produced in the direction the interval repeats in: forwards from the start
+// or backwards from the end.
+func (r Repeating) Iter() *RepeatingIterator {
+	it := &RepeatingIterator{left: r.Repetitions, step: r.Interval.Duration()}
+	if r.Interval.form == durationEnd {
+		it.backwards = true
+		it.cur = r.Interval.End()
+		it.step.Negative = !it.step.Negative
+	} else {
+		it.cur = r.Interval.Start()
+	}
+	return it
+}
+
+// RepeatingIterator iterates over the occurrences of a repeating interval.
+type RepeatingIterator struct {
+	cur       time.Time
+	step      Duration
+	left      int // -1 if unbounded
+	backwards bool
+}
+
+// Next returns the next occurrence. It returns false when all occurrences
+// have been produced.
+func (it *RepeatingIterator) Next() (Interval, bool) {
+	if it.left == 0 {
+		return Interval{}, false
+	}
+	if it.left > 0 {
+		it.left--
+	}
+
+	next := it.step.Shift(it.cur)
+	var occ Interval
+	if it.backwards {
+		occ = NewInterval(next, it.cur)
+	} else {
+		occ = NewInterval(it.cur, next)
+	}
+	it.cur = next
+	return occ, true
+}
@@ -0,0 +1,132 @@
+package iso8601
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanParseRepeating(t *testing.T) {
+	cases := []struct {
+		from string
+		want int
+	}{
+		{"R5/2022-01-01T00:00:00Z/P1W", 5},
+		{"R/2022-01-01T00:00:00Z/P1W", -1},
+		{"R0/2022-01-01T00:00:00Z/2022-01-02T00:00:00Z", 0},
+		{"R2/P1D/2022-05-01T00:00:00Z", 2},
+	}
+	for _, c := range cases {
+		r, err := ParseRepeating(c.from)
+		if err != nil {
+			t.Fatalf("%s: %v", c.from, err)
+		}
+		if r.Repetitions != c.want {
+			t.Fatalf("%s: want=%d, got=%d", c.from, c.want, r.Repetitions)
+		}
+		if got := r.String(); got != c.from {
+			t.Fatalf("want=%s, got=%s", c.from, got)
+		}
+	}
+}
+
+func TestCanRejectBadRepeating(t *testing.T) {
+	cases := []string{
+		"",
+		"R5",
+		"R5/",
+		"5/2022-01-01T00:00:00Z/P1W",
+		"R-1/2022-01-01T00:00:00Z/P1W",
+		"R+1/2022-01-01T00:00:00Z/P1W",
+		"Rx/2022-01-01T00:00:00Z/P1W",
+		"R5/P1W",
+		"R/2022-01-01T00:00:00Z/PT0S",
+		"R/2022-01-01T00:00:00Z/2022-01-01T00:00:00Z",
+	}
+	for _, c := range cases {
+		if _, err := ParseRepeating(c); err == nil {
+			t.Fatalf("%q: expected error, got none", c)
+		}
+	}
+}
+
+func TestRepeatingIter(t *testing.T) {
+	cases := []struct {
+		from string
+		want []string
+	}{
+		{
+			"R3/2022-01-31T00:00:00Z/P1M",
+			[]string{"2022-01-31T00:00:00Z", "2022-03-03T00:00:00Z", "2022-04-03T00:00:00Z"},
+		},
+		{
+			"R3/2022-01-01T00:00:00Z/2022-01-01T12:00:00Z",
+			[]string{"2022-01-01T00:00:00Z", "2022-01-01T12:00:00Z", "2022-01-02T00:00:00Z"},
+		},
+		{
+			"R2/P1D/2022-05-01T00:00:00Z",
+			[]string{"2022-04-30T00:00:00Z", "2022-04-29T00:00:00Z"},
+		},
+		{
+			"R0/2022-01-01T00:00:00Z/P1D",
+			nil,
+		},
+	}
+	for _, c := range cases {
+		r, err := ParseRepeating(c.from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		it := r.Iter()
+		for {
+			occ, ok := it.Next()
+			if !ok {
+				break
+			}
+			got = append(got, occ.Start().Format(time.RFC3339))
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("%s: want=%v, got=%v", c.from, c.want, got)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("%s: want=%v, got=%v", c.from, c.want, got)
+			}
+		}
+	}
+}
+
+func TestRepeatingNext(t *testing.T) {
+	cases := []struct {
+		from   string
+		after  string
+		want   string
+		wantOK bool
+	}{
+		{"R/2022-01-01T00:00:00Z/P1W", "2021-01-01T00:00:00Z", "2022-01-01T00:00:00Z", true},
+		{"R/2022-01-01T00:00:00Z/P1W", "2022-01-01T00:00:00Z", "2022-01-08T00:00:00Z", true},
+		{"R/2022-01-01T00:00:00Z/P1W", "2022-03-02T00:00:00Z", "2022-03-05T00:00:00Z", true},
+		{"R5/2022-01-01T00:00:00Z/P1W", "2022-01-29T00:00:00Z", "", false},
+		{"R5/2022-01-01T00:00:00Z/P1W", "2022-01-28T00:00:00Z", "2022-01-29T00:00:00Z", true},
+		{"R3/P1D/2022-05-01T00:00:00Z", "2022-01-01T00:00:00Z", "2022-04-28T00:00:00Z", true},
+		{"R3/P1D/2022-05-01T00:00:00Z", "2022-04-28T00:00:00Z", "2022-04-29T00:00:00Z", true},
+		{"R3/P1D/2022-05-01T00:00:00Z", "2022-04-30T00:00:00Z", "", false},
+		{"R/P1D/2022-05-01T00:00:00Z", "2022-04-20T12:00:00Z", "2022-04-21T00:00:00Z", true},
+	}
+	for _, c := range cases {
+		r, err := ParseRepeating(c.from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := r.Next(mustParseRFC3339(t, c.after))
+		if ok != c.wantOK {
+			t.Fatalf("%s after %s: want ok=%v, got %v", c.from, c.after, c.wantOK, ok)
+		}
+		if !ok {
+			continue
+		}
+		if want := mustParseRFC3339(t, c.want); !want.Equal(got) {
+			t.Fatalf("%s after %s: want=%s, got=%s", c.from, c.after, want, got)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package iso8601
+
+import (
+	"fmt"
+	"time"
+)
+
+// TemplateFuncs returns functions for text/template and html/template that
+// work with durations. It can be passed to the Funcs method of either
+// template package.
+//
+// The functions are:
+//
+//	parseDuration "P1D"         parses a duration with ParseDurationLenient
+//	humanizeDuration d          writes d like "1 day, 2 hours"
+//	shiftTime d t               returns t shifted by d
+//
+// The duration arguments can be a Duration, a time.Duration or a string,
+// which is parsed like a Duration flag is.
+func TemplateFuncs() map[string]any {
+	return map[string]any{
+		"parseDuration": ParseDurationLenient,
+		"humanizeDuration": func(v any) (string, error) {
+			d, err := toDuration(v)
+			if err != nil {
+				return "", err
+			}
+			return d.Humanize(HumanizeOptions{}), nil
+		},
+		"shiftTime": func(v any, t time.Time) (time.Time, error) {
+			d, err := toDuration(v)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return d.Shift(t), nil
+		},
+	}
+}
+
+func toDuration(v any) (Duration, error) {
+	switch v := v.(type) {
+	case Duration:
+		return v, nil
+	case *Duration:
+		return *v, nil
+	case time.Duration:
+		return FromTimeDuration(v, Hours), nil
+	case string:
+		var d Duration
+		err := d.Set(v)
+		return d, err
+	}
+	return Duration{}, fmt.Errorf("can't use %T as a duration", v)
+}
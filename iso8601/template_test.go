@@ -0,0 +1,33 @@
+package iso8601
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	const text = `{{humanizeDuration .D}}|{{humanizeDuration .TD}}|{{humanizeDuration "P1DT2H"}}|` +
+		`{{(parseDuration "p1w").String}}|{{shiftTime "P1M" .T | printf "%s"}}`
+	tmpl := template.Must(template.New("").Funcs(TemplateFuncs()).Parse(text))
+
+	var b strings.Builder
+	data := struct {
+		D  Duration
+		TD time.Duration
+		T  time.Time
+	}{Duration{Y: 1}, 90 * time.Minute, mustParseRFC3339(t, "2022-01-31T00:00:00Z")}
+	if err := tmpl.Execute(&b, data); err != nil {
+		t.Fatal(err)
+	}
+	const want = "1 year|1 hour, 30 minutes|1 day, 2 hours|P1W|2022-03-03 00:00:00 +0000 UTC"
+	if got := b.String(); got != want {
+		t.Fatalf("want=%s, got=%s", want, got)
+	}
+
+	tmpl = template.Must(template.New("").Funcs(TemplateFuncs()).Parse(`{{humanizeDuration 1}}`))
+	if err := tmpl.Execute(&b, nil); err == nil {
+		t.Fatal("want error, got none")
+	}
+}
@@ -0,0 +1,271 @@
+package iso8601
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AlignOptions control how Duration.Truncate, Duration.Round and
+// Duration.Buckets align times to periods.
+type AlignOptions struct {
+	// Location is the location whose calendar the periods follow. Nil means
+	// the location of the time being aligned.
+	Location *time.Location
+	// WeekStart is the day weeks start on. The zero value is Sunday, so set
+	// it to time.Monday for ISO 8601 weeks.
+	WeekStart time.Weekday
+}
+
+// aligner splits time into periods of a duration.
+type aligner struct {
+	unit Unit          // Years to Days, or Hours for a time part only
+	n    int           // number of units, if unit isn't Hours
+	step time.Duration // length of the time part, if unit is Hours
+	opts AlignOptions
+}
+
+func (d Duration) aligner(opts AlignOptions) (aligner, error) {
+	a := aligner{opts: opts}
+	if d.Negative || d.IsZero() {
+		return a, errors.New("can't align to a negative or zero duration")
+	}
+
+	if d.Y == 0 && d.M == 0 && d.W == 0 && d.D == 0 && (d.Frac == 0 || d.FracUnit >= Hours) {
+		a.unit, a.step = Hours, d.timeDuration()
+		if a.step <= 0 || a.step > 24*time.Hour {
+			return a, fmt.Errorf("can't align to %s: time periods must be up to a day long", d)
+		}
+		return a, nil
+	}
+
+	if d.HasTimePart() || d.Frac != 0 {
+		return a, fmt.Errorf("can't align to %s: calendar periods must have a single whole component", d)
+	}
+	for u := Years; u <= Days; u++ {
+		n := *d.field(u)
+		if n == 0 {
+			continue
+		}
+		if a.n != 0 || n < 0 {
+			return a, fmt.Errorf("can't align to %s: calendar periods must have a single whole component", d)
+		}
+		a.unit, a.n = u, n
+	}
+	return a, nil
+}
+
+// start returns the start of the period t is in.
+func (a aligner) start(t time.Time) time.Time {
+	if a.opts.Location != nil {
+		t = t.In(a.opts.Location)
+	}
+	y, m, d := t.Date()
+	loc := t.Location()
+
+	switch a.unit {
+	case Years:
+		return time.Date(y-floorMod(y, a.n), time.January, 1, 0, 0, 0, 0, loc)
+	case Months:
+		i := y*12 + int(m) - 1
+		i -= floorMod(i, a.n)
+		return time.Date(0, time.Month(i+1), 1, 0, 0, 0, 0, loc)
+	case Weeks:
+		// January 1, 1970 is a Thursday.
+		first := floorMod(int(a.opts.WeekStart)-int(time.Thursday), 7)
+		week := floorDiv(epochDay(y, m, d)-first, 7)
+		back := floorMod(int(t.Weekday())-int(a.opts.WeekStart), 7) + floorMod(week, a.n)*7
+		return time.Date(y, m, d-back, 0, 0, 0, 0, loc)
+	case Days:
+		return time.Date(y, m, d-floorMod(epochDay(y, m, d), a.n), 0, 0, 0, 0, loc)
+	}
+
+	// Of the instants clocks show a period start at, take the latest one not
+	// after t. That is the second one in the hour repeated when clocks go
+	// back, and an earlier period if clocks skipped the start of this one to
+	// past t.
+	var start time.Time
+	lo, hi := a.window(t)
+	for k := hi; k >= 0 && (k >= lo || start.IsZero()); k-- {
+		for _, b := range a.boundaries(y, m, d, k, loc) {
+			if !b.After(t) && b.After(start) {
+				start = b
+			}
+		}
+	}
+	if start.IsZero() {
+		return a.boundaries(y, m, d, 0, loc)[0]
+	}
+	return start
+}
+
+// end returns the end of the period that begins at start, which is also the
+// start of the next one. Time periods that don't divide a day evenly are cut
+// short at midnight.
+func (a aligner) end(start time.Time) time.Time {
+	if a.unit != Hours {
+		var p Duration
+		*p.field(a.unit) = a.n
+		return p.Shift(start)
+	}
+	y, m, d := start.Date()
+	loc := start.Location()
+	end := a.boundaries(y, m, d+1, 0, loc)[0]
+	var found bool
+	lo, hi := a.window(start)
+	for k := lo; time.Duration(k)*a.step < 24*time.Hour && (k <= hi || !found); k++ {
+		for _, b := range a.boundaries(y, m, d, k, loc) {
+			if b.After(start) && b.Before(end) {
+				end, found = b, true
+			}
+		}
+	}
+	return end
+}
+
+// window returns the range of k of the period starts clocks may show close
+// to t. It is just the period of the time of day of t, unless clocks change
+// within three hours of t: then they show some times twice, and the range
+// covers the periods within three hours of the time of day.
+func (a aligner) window(t time.Time) (lo, hi int) {
+	clock := wallClock(t)
+	lo, hi = int(clock/a.step), int(clock/a.step)
+	_, offset := t.Zone()
+	_, before := t.Add(-3 * time.Hour).Zone()
+	_, after := t.Add(3 * time.Hour).Zone()
+	if before == offset && after == offset {
+		return lo, hi
+	}
+	lo, hi = int((clock-3*time.Hour)/a.step), int((clock+3*time.Hour)/a.step)
+	if lo < 0 {
+		lo = 0
+	}
+	if last := int((24*time.Hour - 1) / a.step); hi > last {
+		hi = last
+	}
+	return lo, hi
+}
+
+// boundaries returns the instants when clocks in loc show the start of the
+// k-th time period of the day, in order. There are two of them in the hour
+// repeated when clocks go back. A start that clocks skip when they go
+// forward is the instant it would be if they hadn't, so a start at 02:00
+// becomes 03:00 when clocks skip from 02:00 to 03:00.
+func (a aligner) boundaries(y int, m time.Month, d, k int, loc *time.Location) []time.Time {
+	clock := int(time.Duration(k) * a.step)
+	b := time.Date(y, m, d, 0, 0, 0, clock, loc)
+	// time.Date may resolve a skipped time to before the skip, which would
+	// make it earlier than the wall clock time asked for.
+	by, bm, bd := b.Date()
+	if behind := time.Date(y, m, d, 0, 0, 0, clock, time.UTC).Sub(time.Date(by, bm, bd, 0, 0, 0, int(wallClock(b)), time.UTC)); behind > 0 {
+		b = b.Add(behind)
+	}
+	_, offset := b.Zone()
+	for _, near := range []time.Time{b.Add(-3 * time.Hour), b.Add(3 * time.Hour)} {
+		_, nearOffset := near.Zone()
+		if nearOffset == offset {
+			continue
+		}
+		other := b.Add(time.Duration(offset-nearOffset) * time.Second)
+		if _, otherOffset := other.Zone(); otherOffset != nearOffset {
+			continue
+		}
+		if other.Before(b) {
+			return []time.Time{other, b}
+		}
+		return []time.Time{b, other}
+	}
+	return []time.Time{b}
+}
+
+// wallClock returns the time of day of t as shown by a clock.
+func wallClock(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(s)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// epochDay returns the number of days since January 1, 1970.
+func epochDay(y int, m time.Month, d int) int {
+	// Midnight in UTC is a whole number of days since the epoch.
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60))
+}
+
+func floorDiv(a, b int) int {
+	if a < 0 {
+		return -((-a + b - 1) / b)
+	}
+	return a / b
+}
+
+func floorMod(a, b int) int {
+	return a - floorDiv(a, b)*b
+}
+
+// Truncate returns the start of the period of duration d that t falls in,
+// which is the calendar version of time.Time.Truncate. For example, P1M
+// truncates to the start of the month and PT15M to the quarter of an hour.
+//
+// d must be either a time part up to a day long, such as PT15M or PT1H30M,
+// or a single whole calendar component, such as P1W, P3M or P1Y. Time
+// periods are aligned to the wall clock from midnight. Calendar periods of
+// several units are counted from January 1, 1970: P2D periods start on even
+// days since then, P3M periods on January, April, July and October, and P10Y
+// periods on decades. The result is in opts.Location, if it is set.
+func (d Duration) Truncate(t time.Time, opts AlignOptions) (time.Time, error) {
+	a, err := d.aligner(opts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return a.start(t), nil
+}
+
+// Round returns the start of the period of duration d that t falls in, or
+// of the next one, whichever is nearer. Halfway values are rounded up. The
+// durations allowed are the same as for Truncate.
+func (d Duration) Round(t time.Time, opts AlignOptions) (time.Time, error) {
+	a, err := d.aligner(opts)
+	if err != nil {
+		return time.Time{}, err
+	}
+	start := a.start(t)
+	end := a.end(start)
+	if t.Sub(start) < end.Sub(t) {
+		return start, nil
+	}
+	return end, nil
+}
+
+// Buckets returns an iterator over the consecutive periods of duration d
+// that cover the time from from up to, but not including, to. The first one
+// starts at from truncated by Truncate. The durations allowed are the same
+// as for Truncate.
+func (d Duration) Buckets(from, to time.Time, opts AlignOptions) (*BucketIterator, error) {
+	a, err := d.aligner(opts)
+	if err != nil {
+		return nil, err
+	}
+	it := &BucketIterator{a: a, cur: to, to: to}
+	if from.Before(to) {
+		it.cur = a.start(from)
+	}
+	return it, nil
+}
+
+// BucketIterator iterates over aligned periods of time.
+type BucketIterator struct {
+	a       aligner
+	cur, to time.Time
+}
+
+// Next returns the next period. It returns false when the periods cover the
+// time requested.
+func (it *BucketIterator) Next() (Interval, bool) {
+	if !it.cur.Before(it.to) {
+		return Interval{}, false
+	}
+	end := it.a.end(it.cur)
+	i := NewInterval(it.cur, end)
+	it.cur = end
+	return i, true
+}
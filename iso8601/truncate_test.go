@@ -0,0 +1,261 @@
+package iso8601
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		dur  string
+		t    string
+		opts AlignOptions
+		want string
+	}{
+		{"P1Y", "2022-05-17T10:15:00Z", AlignOptions{}, "2022-01-01T00:00:00Z"},
+		{"P10Y", "2022-05-17T10:15:00Z", AlignOptions{}, "2020-01-01T00:00:00Z"},
+		{"P1M", "2022-05-17T10:15:00Z", AlignOptions{}, "2022-05-01T00:00:00Z"},
+		{"P3M", "2022-05-17T10:15:00Z", AlignOptions{}, "2022-04-01T00:00:00Z"},
+		{"P3M", "1969-02-17T10:15:00Z", AlignOptions{}, "1969-01-01T00:00:00Z"},
+		// May 17, 2022 is a Tuesday.
+		{"P1W", "2022-05-17T10:15:00Z", AlignOptions{}, "2022-05-15T00:00:00Z"},
+		{"P1W", "2022-05-17T10:15:00Z", AlignOptions{WeekStart: time.Monday}, "2022-05-16T00:00:00Z"},
+		{"P1W", "2022-05-15T10:15:00Z", AlignOptions{WeekStart: time.Monday}, "2022-05-09T00:00:00Z"},
+		{"P2W", "2022-05-17T10:15:00Z", AlignOptions{WeekStart: time.Monday}, "2022-05-16T00:00:00Z"},
+		{"P2W", "2022-05-24T10:15:00Z", AlignOptions{WeekStart: time.Monday}, "2022-05-16T00:00:00Z"},
+		{"P1D", "2022-05-17T10:15:00Z", AlignOptions{}, "2022-05-17T00:00:00Z"},
+		{"P2D", "1970-01-04T10:15:00Z", AlignOptions{}, "1970-01-03T00:00:00Z"},
+		{"PT15M", "2022-05-17T10:29:59.9Z", AlignOptions{}, "2022-05-17T10:15:00Z"},
+		{"PT1H", "2022-05-17T10:29:59+05:30", AlignOptions{}, "2022-05-17T10:00:00+05:30"},
+		{"PT7H", "2022-05-17T23:00:00Z", AlignOptions{}, "2022-05-17T21:00:00Z"},
+		{"P1D", "2022-05-17T01:00:00Z", AlignOptions{Location: time.FixedZone("", -3*60*60)}, "2022-05-16T00:00:00-03:00"},
+	}
+	for _, c := range cases {
+		d, err := ParseDuration(c.dur)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := d.Truncate(mustParseRFC3339(t, c.t), c.opts)
+		if err != nil {
+			t.Fatalf("%s, %s: %v", c.dur, c.t, err)
+		}
+		if want := mustParseRFC3339(t, c.want); !want.Equal(got) {
+			t.Fatalf("%s, %s: want=%s, got=%s", c.dur, c.t, want, got)
+		}
+	}
+}
+
+func TestTruncateFollowsWallClock(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clocks went from 02:00 to 03:00 on March 27, 2022.
+	tm := time.Date(2022, time.March, 27, 15, 10, 0, 0, loc)
+	got, err := (Duration{TH: 1}).Truncate(tm, AlignOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2022, time.March, 27, 15, 0, 0, 0, loc); !want.Equal(got) {
+		t.Fatalf("want=%s, got=%s", want, got)
+	}
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clocks went from 02:00 to 03:00 on March 13, 2022, so periods that
+	// start at 02:00 start at 03:00 instead.
+	spring := []struct {
+		dur  string
+		t    string
+		want string
+	}{
+		{"PT2H", "2022-03-13T03:30:00-04:00", "2022-03-13T03:00:00-04:00"},
+		{"PT2H", "2022-03-13T04:30:00-04:00", "2022-03-13T04:00:00-04:00"},
+		{"PT3H", "2022-03-13T03:30:00-04:00", "2022-03-13T03:00:00-04:00"},
+		{"PT7H", "2022-03-13T10:00:00-04:00", "2022-03-13T07:00:00-04:00"},
+		{"PT90M", "2022-03-13T03:30:00-04:00", "2022-03-13T03:00:00-04:00"},
+	}
+	for _, c := range spring {
+		d, err := ParseDuration(c.dur)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := d.Truncate(mustParseRFC3339(t, c.t).In(ny), AlignOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := mustParseRFC3339(t, c.want); !want.Equal(got) {
+			t.Fatalf("%s, %s: want=%s, got=%s", c.dur, c.t, want, got)
+		}
+		if again, _ := d.Truncate(got, AlignOptions{}); !again.Equal(got) {
+			t.Fatalf("%s, %s: truncating %s again gives %s", c.dur, c.t, got, again)
+		}
+	}
+
+	// Clocks went from 02:00 back to 01:00 on November 6, 2022, so 01:30
+	// EST comes an hour after 01:30 EDT.
+	est := mustParseRFC3339(t, "2022-11-06T01:30:00-05:00").In(ny)
+	cases := []struct {
+		dur   string
+		round bool
+		want  string
+	}{
+		{"PT15M", false, "2022-11-06T01:30:00-05:00"},
+		{"PT1H", false, "2022-11-06T01:00:00-05:00"},
+		{"PT15M", true, "2022-11-06T01:30:00-05:00"},
+		{"PT1H", true, "2022-11-06T02:00:00-05:00"},
+	}
+	for _, c := range cases {
+		d, err := ParseDuration(c.dur)
+		if err != nil {
+			t.Fatal(err)
+		}
+		truncate := d.Truncate
+		if c.round {
+			truncate = d.Round
+		}
+		got, err := truncate(est, AlignOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := mustParseRFC3339(t, c.want); !want.Equal(got) {
+			t.Fatalf("%s, round=%v: want=%s, got=%s", c.dur, c.round, want, got)
+		}
+	}
+
+	it, err := (Duration{TH: 1}).Buckets(est.Add(-time.Hour), est, AlignOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range [][2]string{
+		{"2022-11-06T01:00:00-04:00", "2022-11-06T01:00:00-05:00"},
+		{"2022-11-06T01:00:00-05:00", "2022-11-06T02:00:00-05:00"},
+	} {
+		i, ok := it.Next()
+		if !ok || !i.Start().Equal(mustParseRFC3339(t, want[0])) || !i.End().Equal(mustParseRFC3339(t, want[1])) {
+			t.Fatalf("want=%s/%s, got=%s", want[0], want[1], i)
+		}
+	}
+	if i, ok := it.Next(); ok {
+		t.Fatalf("want no more buckets, got %s", i)
+	}
+}
+
+func TestRound(t *testing.T) {
+	cases := []struct {
+		dur  string
+		t    string
+		want string
+	}{
+		{"P1M", "2022-02-14T23:59:59Z", "2022-02-01T00:00:00Z"},
+		{"P1M", "2022-02-15T00:00:00Z", "2022-03-01T00:00:00Z"},
+		{"PT15M", "2022-05-17T10:22:29Z", "2022-05-17T10:15:00Z"},
+		{"PT15M", "2022-05-17T10:22:30Z", "2022-05-17T10:30:00Z"},
+		{"PT7H", "2022-05-17T23:00:00Z", "2022-05-18T00:00:00Z"},
+	}
+	for _, c := range cases {
+		d, err := ParseDuration(c.dur)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := d.Round(mustParseRFC3339(t, c.t), AlignOptions{})
+		if err != nil {
+			t.Fatalf("%s, %s: %v", c.dur, c.t, err)
+		}
+		if want := mustParseRFC3339(t, c.want); !want.Equal(got) {
+			t.Fatalf("%s, %s: want=%s, got=%s", c.dur, c.t, want, got)
+		}
+	}
+}
+
+func TestCanRejectBadAlignment(t *testing.T) {
+	for _, s := range []string{"P0D", "-P1D", "P1M1D", "P1DT1H", "P1.5D", "PT25H"} {
+		d, err := ParseDuration(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := d.Truncate(time.Now(), AlignOptions{}); err == nil {
+			t.Fatalf("%s: expected error, got none", s)
+		}
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	cases := []struct {
+		dur      string
+		from, to string
+		want     []string
+	}{
+		{
+			"P1M", "2022-01-15T00:00:00Z", "2022-03-01T00:00:00Z",
+			[]string{"2022-01-01T00:00:00Z/2022-02-01T00:00:00Z", "2022-02-01T00:00:00Z/2022-03-01T00:00:00Z"},
+		},
+		{
+			"PT10H", "2022-01-01T05:00:00Z", "2022-01-02T01:00:00Z",
+			[]string{
+				"2022-01-01T00:00:00Z/2022-01-01T10:00:00Z",
+				"2022-01-01T10:00:00Z/2022-01-01T20:00:00Z",
+				"2022-01-01T20:00:00Z/2022-01-02T00:00:00Z",
+				"2022-01-02T00:00:00Z/2022-01-02T10:00:00Z",
+			},
+		},
+		{"P1W", "2022-01-01T00:00:00Z", "2022-01-01T00:00:00Z", nil},
+	}
+	for _, c := range cases {
+		d, err := ParseDuration(c.dur)
+		if err != nil {
+			t.Fatal(err)
+		}
+		it, err := d.Buckets(mustParseRFC3339(t, c.from), mustParseRFC3339(t, c.to), AlignOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for {
+			i, ok := it.Next()
+			if !ok {
+				break
+			}
+			got = append(got, i.String())
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("%s: want=%v, got=%v", c.dur, c.want, got)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("%s: want=%v, got=%v", c.dur, c.want, got)
+			}
+		}
+	}
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clocks went from 02:00 to 03:00 on March 13, 2022. The buckets follow
+	// the wall clock, like Truncate does.
+	from := time.Date(2022, time.March, 13, 0, 0, 0, 0, ny)
+	it, err := (Duration{TH: 7}).Buckets(from, from.AddDate(0, 0, 1), AlignOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"2022-03-13T00:00:00-05:00", "2022-03-13T07:00:00-04:00",
+		"2022-03-13T14:00:00-04:00", "2022-03-13T21:00:00-04:00",
+		"2022-03-14T00:00:00-04:00",
+	}
+	for i := 0; i < len(want)-1; i++ {
+		b, ok := it.Next()
+		if !ok || !b.Start().Equal(mustParseRFC3339(t, want[i])) || !b.End().Equal(mustParseRFC3339(t, want[i+1])) {
+			t.Fatalf("want=%s/%s, got=%s", want[i], want[i+1], b)
+		}
+		if start, _ := (Duration{TH: 7}).Truncate(b.End().Add(-time.Second), AlignOptions{}); !start.Equal(b.Start()) {
+			t.Fatalf("%s: truncating its last second gives %s", b, start)
+		}
+	}
+	if b, ok := it.Next(); ok {
+		t.Fatalf("want no more buckets, got %s", b)
+	}
+}
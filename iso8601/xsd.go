@@ -0,0 +1,309 @@
+package iso8601
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// XSDDuration is a Duration in the lexical space of the XML Schema
+// xs:duration type, such as -P1Y2M3DT4H5M6.7S. Unlike ISO 8601, it has no
+// weeks, no alternative format, no plus sign and no decimal comma, and only
+// seconds can have a fraction.
+//
+// See https://www.w3.org/TR/xmlschema-2/#duration.
+type XSDDuration Duration
+
+// DayTimeDuration is an XSDDuration restricted to days, hours, minutes and
+// seconds, like the xs:dayTimeDuration type.
+type DayTimeDuration Duration
+
+// YearMonthDuration is an XSDDuration restricted to years and months, like
+// the xs:yearMonthDuration type.
+type YearMonthDuration Duration
+
+// xsdType describes one of the XML Schema duration types by the range of
+// units it allows.
+type xsdType struct {
+	name   string
+	lo, hi Unit
+}
+
+var (
+	xsDuration          = xsdType{"xs:duration", Years, Seconds}
+	xsDayTimeDuration   = xsdType{"xs:dayTimeDuration", Days, Seconds}
+	xsYearMonthDuration = xsdType{"xs:yearMonthDuration", Years, Months}
+)
+
+// ParseXSDDuration parses s as an xs:duration.
+func ParseXSDDuration(s string) (XSDDuration, error) {
+	d, err := xsDuration.parse(s)
+	return XSDDuration(d), err
+}
+
+// ParseDayTimeDuration parses s as an xs:dayTimeDuration.
+func ParseDayTimeDuration(s string) (DayTimeDuration, error) {
+	d, err := xsDayTimeDuration.parse(s)
+	return DayTimeDuration(d), err
+}
+
+// ParseYearMonthDuration parses s as an xs:yearMonthDuration.
+func ParseYearMonthDuration(s string) (YearMonthDuration, error) {
+	d, err := xsYearMonthDuration.parse(s)
+	return YearMonthDuration(d), err
+}
+
+// parse parses s with ParseDuration and then checks that it only uses what
+// the type allows. Errors are of type *ParseError.
+func (t xsdType) parse(s string) (Duration, error) {
+	d, err := ParseDuration(s)
+	if err != nil {
+		return Duration{}, err
+	}
+	if p := strings.IndexByte(s, 'P'); isAlternative(s[p+1:]) {
+		return Duration{}, &ParseError{Input: s, Offset: p + 1, Reason: "alternative format not allowed in " + t.name}
+	}
+
+	var inTime bool
+	for i := 0; i < len(s); i++ {
+		var ok bool
+		switch c := s[i]; {
+		case c == '-':
+			ok = i == 0
+		case c == 'P' || isDigit(c):
+			ok = true
+		case c == 'T':
+			inTime, ok = true, t.hi >= Hours
+		case c == '.':
+			j := i + 1
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+			ok = j < len(s) && s[j] == 'S'
+		default:
+			u := designatorUnit(c, inTime)
+			ok = u != Weeks && t.lo <= u && u <= t.hi
+		}
+		if !ok {
+			return Duration{}, &ParseError{Input: s, Offset: i, Reason: fmt.Sprintf("%q not allowed in %s", s[i], t.name)}
+		}
+	}
+	return d, nil
+}
+
+// check reports whether d can be written as the type.
+func (t xsdType) check(d Duration) error {
+	if d.W != 0 {
+		return fmt.Errorf("%s can't have weeks", t.name)
+	}
+	if d.Frac != 0 && d.FracUnit != Seconds {
+		return fmt.Errorf("%s allows a fraction of seconds only", t.name)
+	}
+	for u := Years; u <= Seconds; u++ {
+		if (u < t.lo || u > t.hi) && *d.field(u) != 0 {
+			return fmt.Errorf("%s can't have %s", t.name, u)
+		}
+	}
+	return nil
+}
+
+func (t xsdType) string(d Duration) string {
+	if d.IsZero() && t.hi < Days {
+		return "P0M"
+	}
+	return d.String()
+}
+
+func (t xsdType) marshal(d Duration) ([]byte, error) {
+	if err := t.check(d); err != nil {
+		return nil, err
+	}
+	return []byte(t.string(d)), nil
+}
+
+// unmarshal parses b into d. Surrounding whitespace is ignored, as all the
+// duration types collapse it.
+func (t xsdType) unmarshal(d *Duration, b []byte) error {
+	tmp, err := t.parse(strings.TrimSpace(string(b)))
+	if err != nil {
+		return err
+	}
+	*d = tmp
+	return nil
+}
+
+func (t xsdType) marshalXML(d Duration, e *xml.Encoder, start xml.StartElement) error {
+	b, err := t.marshal(d)
+	if err != nil {
+		return err
+	}
+	return e.EncodeElement(string(b), start)
+}
+
+func (t xsdType) unmarshalXML(d *Duration, dec *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := dec.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	return t.unmarshal(d, []byte(s))
+}
+
+func (t xsdType) marshalXMLAttr(d Duration, name xml.Name) (xml.Attr, error) {
+	b, err := t.marshal(d)
+	if err != nil {
+		return xml.Attr{}, err
+	}
+	return xml.Attr{Name: name, Value: string(b)}, nil
+}
+
+// xsdReferences are the dateTimes XML Schema orders durations by.
+var xsdReferences = [...]time.Time{
+	time.Date(1696, time.September, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1697, time.February, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1903, time.March, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1903, time.July, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// xsdCompare compares a and b by adding them to each of the reference
+// dateTimes. It reports false if the results don't agree, which makes the
+// durations incomparable.
+func xsdCompare(a, b Duration) (int, bool) {
+	opts := ShiftOptions{MonthEnd: MonthEndClamp}
+	var c int
+	for i, ref := range xsdReferences {
+		// Clamping to the end of month never fails.
+		ta, _ := a.ShiftWith(ref, opts)
+		tb, _ := b.ShiftWith(ref, opts)
+		var ci int
+		switch {
+		case ta.Before(tb):
+			ci = -1
+		case ta.After(tb):
+			ci = 1
+		}
+		if i > 0 && ci != c {
+			return 0, false
+		}
+		c = ci
+	}
+	return c, true
+}
+
+func (x XSDDuration) String() string { return xsDuration.string(Duration(x)) }
+
+// MarshalText satisfies encoding.TextMarshaler. It returns an error if x
+// has weeks or a fraction of anything but seconds.
+func (x XSDDuration) MarshalText() ([]byte, error) { return xsDuration.marshal(Duration(x)) }
+
+// UnmarshalText satisfies encoding.TextUnmarshaler.
+func (x *XSDDuration) UnmarshalText(b []byte) error {
+	return xsDuration.unmarshal((*Duration)(x), b)
+}
+
+// MarshalXML satisfies xml.Marshaler.
+func (x XSDDuration) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return xsDuration.marshalXML(Duration(x), e, start)
+}
+
+// UnmarshalXML satisfies xml.Unmarshaler.
+func (x *XSDDuration) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return xsDuration.unmarshalXML((*Duration)(x), d, start)
+}
+
+// MarshalXMLAttr satisfies xml.MarshalerAttr.
+func (x XSDDuration) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xsDuration.marshalXMLAttr(Duration(x), name)
+}
+
+// UnmarshalXMLAttr satisfies xml.UnmarshalerAttr.
+func (x *XSDDuration) UnmarshalXMLAttr(attr xml.Attr) error {
+	return xsDuration.unmarshal((*Duration)(x), []byte(attr.Value))
+}
+
+// Compare compares x and y in the partial order of xs:duration. It returns
+// -1, 0 or +1 and true if x is less than, equal to or greater than y, and
+// false if they are incomparable, such as P1M and P30D.
+func (x XSDDuration) Compare(y XSDDuration) (int, bool) {
+	return xsdCompare(Duration(x), Duration(y))
+}
+
+func (x DayTimeDuration) String() string { return xsDayTimeDuration.string(Duration(x)) }
+
+// MarshalText satisfies encoding.TextMarshaler. It returns an error if x
+// has years, months, weeks or a fraction of anything but seconds.
+func (x DayTimeDuration) MarshalText() ([]byte, error) {
+	return xsDayTimeDuration.marshal(Duration(x))
+}
+
+// UnmarshalText satisfies encoding.TextUnmarshaler.
+func (x *DayTimeDuration) UnmarshalText(b []byte) error {
+	return xsDayTimeDuration.unmarshal((*Duration)(x), b)
+}
+
+// MarshalXML satisfies xml.Marshaler.
+func (x DayTimeDuration) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return xsDayTimeDuration.marshalXML(Duration(x), e, start)
+}
+
+// UnmarshalXML satisfies xml.Unmarshaler.
+func (x *DayTimeDuration) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return xsDayTimeDuration.unmarshalXML((*Duration)(x), d, start)
+}
+
+// MarshalXMLAttr satisfies xml.MarshalerAttr.
+func (x DayTimeDuration) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xsDayTimeDuration.marshalXMLAttr(Duration(x), name)
+}
+
+// UnmarshalXMLAttr satisfies xml.UnmarshalerAttr.
+func (x *DayTimeDuration) UnmarshalXMLAttr(attr xml.Attr) error {
+	return xsDayTimeDuration.unmarshal((*Duration)(x), []byte(attr.Value))
+}
+
+// Compare returns -1, 0 or +1 if x is shorter than, as long as or longer
+// than y. Day and time durations are totally ordered.
+func (x DayTimeDuration) Compare(y DayTimeDuration) int {
+	c, _ := xsdCompare(Duration(x), Duration(y))
+	return c
+}
+
+func (x YearMonthDuration) String() string { return xsYearMonthDuration.string(Duration(x)) }
+
+// MarshalText satisfies encoding.TextMarshaler. It returns an error if x
+// has anything but years and months, or a fraction.
+func (x YearMonthDuration) MarshalText() ([]byte, error) {
+	return xsYearMonthDuration.marshal(Duration(x))
+}
+
+// UnmarshalText satisfies encoding.TextUnmarshaler.
+func (x *YearMonthDuration) UnmarshalText(b []byte) error {
+	return xsYearMonthDuration.unmarshal((*Duration)(x), b)
+}
+
+// MarshalXML satisfies xml.Marshaler.
+func (x YearMonthDuration) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return xsYearMonthDuration.marshalXML(Duration(x), e, start)
+}
+
+// UnmarshalXML satisfies xml.Unmarshaler.
+func (x *YearMonthDuration) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return xsYearMonthDuration.unmarshalXML((*Duration)(x), d, start)
+}
+
+// MarshalXMLAttr satisfies xml.MarshalerAttr.
+func (x YearMonthDuration) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xsYearMonthDuration.marshalXMLAttr(Duration(x), name)
+}
+
+// UnmarshalXMLAttr satisfies xml.UnmarshalerAttr.
+func (x *YearMonthDuration) UnmarshalXMLAttr(attr xml.Attr) error {
+	return xsYearMonthDuration.unmarshal((*Duration)(x), []byte(attr.Value))
+}
+
+// Compare returns -1, 0 or +1 if x is shorter than, as long as or longer
+// than y. Year and month durations are totally ordered.
+func (x YearMonthDuration) Compare(y YearMonthDuration) int {
+	c, _ := xsdCompare(Duration(x), Duration(y))
+	return c
+}
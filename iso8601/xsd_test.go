@@ -0,0 +1,174 @@
+package iso8601
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+)
+
+func TestCanParseXSDDuration(t *testing.T) {
+	cases := []struct {
+		from string
+		want Duration
+	}{
+		{"P1Y2M3DT4H5M6.7S", Duration{Y: 1, M: 2, D: 3, TH: 4, TM: 5, TS: 6, Frac: 700000000, FracUnit: Seconds}},
+		{"-P120D", Duration{D: 120, Negative: true}},
+		{"PT36H", Duration{TH: 36}},
+		{"P0D", Duration{}},
+	}
+	for _, c := range cases {
+		got, err := ParseXSDDuration(c.from)
+		if err != nil {
+			t.Fatalf("%s: %v", c.from, err)
+		}
+		if Duration(got) != c.want {
+			t.Fatalf("%s: want=%+v, got=%+v", c.from, c.want, got)
+		}
+	}
+}
+
+func TestCanRejectBadXSDDuration(t *testing.T) {
+	cases := []struct {
+		from       string
+		parse      func(string) error
+		wantOffset int
+	}{
+		{"P1W", parseXSD, 2},
+		{"+P1D", parseXSD, 0},
+		{"PT1,5S", parseXSD, 3},
+		{"P1.5D", parseXSD, 2},
+		{"PT1.5M", parseXSD, 3},
+		{"P0003-06-04T12:30:05", parseXSD, 1},
+		{"P00030604", parseXSD, 1},
+		{"P", parseXSD, 1},
+		{"P1Y", parseDayTime, 2},
+		{"P1DT1M2M", parseDayTime, 7},
+		{"PT1H", parseYearMonth, 1},
+		{"P1Y1D", parseYearMonth, 4},
+	}
+	for _, c := range cases {
+		var perr *ParseError
+		if err := c.parse(c.from); !errors.As(err, &perr) {
+			t.Fatalf("%q: want *ParseError, got %v", c.from, err)
+		}
+		if perr.Offset != c.wantOffset {
+			t.Fatalf("%q: want offset %d, got %d (%v)", c.from, c.wantOffset, perr.Offset, perr)
+		}
+	}
+}
+
+func parseXSD(s string) error {
+	_, err := ParseXSDDuration(s)
+	return err
+}
+
+func parseDayTime(s string) error {
+	_, err := ParseDayTimeDuration(s)
+	return err
+}
+
+func parseYearMonth(s string) error {
+	_, err := ParseYearMonthDuration(s)
+	return err
+}
+
+type xsdDoc struct {
+	XMLName   xml.Name          `xml:"doc"`
+	Timeout   DayTimeDuration   `xml:"timeout,attr"`
+	Term      YearMonthDuration `xml:"term"`
+	Retention XSDDuration       `xml:"retention"`
+}
+
+func TestCanRoundTripXSDDurationsThroughXML(t *testing.T) {
+	const want = `<doc timeout="PT1M30.5S"><term>P1Y6M</term><retention>P1Y2DT3H</retention></doc>`
+
+	var doc xsdDoc
+	in := `<doc timeout=" PT1M30.5S "><term>
+		P1Y6M
+	</term><retention>P1Y2DT3H</retention></doc>`
+	if err := xml.Unmarshal([]byte(in), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if w := (DayTimeDuration{TM: 1, TS: 30, Frac: 500000000, FracUnit: Seconds}); doc.Timeout != w {
+		t.Fatalf("want=%+v, got=%+v", w, doc.Timeout)
+	}
+	if w := (YearMonthDuration{Y: 1, M: 6}); doc.Term != w {
+		t.Fatalf("want=%+v, got=%+v", w, doc.Term)
+	}
+
+	got, err := xml.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("want=%s, got=%s", want, got)
+	}
+
+	if err := xml.Unmarshal([]byte(`<doc timeout="P1M"/>`), &doc); err == nil {
+		t.Fatal("want error for a month in xs:dayTimeDuration, got none")
+	}
+}
+
+func TestCanRejectBadXSDDurationOnMarshal(t *testing.T) {
+	cases := []struct {
+		name string
+		v    any
+	}{
+		{"weeks", XSDDuration{W: 1}},
+		{"fraction of days", XSDDuration{D: 1, Frac: 500000000, FracUnit: Days}},
+		{"years in day and time", DayTimeDuration{Y: 1}},
+		{"days in year and month", YearMonthDuration{Y: 1, D: 1}},
+	}
+	for _, c := range cases {
+		if _, err := xml.Marshal(c.v); err == nil {
+			t.Fatalf("%s: want error, got none", c.name)
+		}
+	}
+
+	if got := (YearMonthDuration{}).String(); got != "P0M" {
+		t.Fatalf("want=P0M, got=%s", got)
+	}
+}
+
+func TestXSDDurationCompare(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		want   int
+		wantOK bool
+	}{
+		{"P1Y", "P12M", 0, true},
+		{"P1Y", "P364D", 1, true},
+		{"P1Y", "P365D", 0, false},
+		{"P1Y", "P366D", 0, false},
+		{"P1Y", "P367D", -1, true},
+		{"P1M", "P27D", 1, true},
+		{"P1M", "P28D", 0, false},
+		{"P1M", "P31D", 0, false},
+		{"P1M", "P32D", -1, true},
+		{"P5M", "P149D", 1, true},
+		{"P5M", "P154D", -1, true},
+		{"PT24H", "P1D", 0, true},
+		{"-P1D", "PT1S", -1, true},
+	}
+	for _, c := range cases {
+		a, err := ParseXSDDuration(c.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ParseXSDDuration(c.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := a.Compare(b)
+		if got != c.want || ok != c.wantOK {
+			t.Fatalf("%s <=> %s: want=%d, %v, got=%d, %v", c.a, c.b, c.want, c.wantOK, got, ok)
+		}
+	}
+
+	if got := (DayTimeDuration{TH: 48}).Compare(DayTimeDuration{D: 1, TH: 23}); got != 1 {
+		t.Fatalf("want=1, got=%d", got)
+	}
+	if got := (YearMonthDuration{M: 11}).Compare(YearMonthDuration{Y: 1}); got != -1 {
+		t.Fatalf("want=-1, got=%d", got)
+	}
+}
@@ -11,9 +11,23 @@ Run this:
 */
 package main
 
+/*
+// Statuses WatchTime stores in its status argument.
+enum {
+	WATCHTIME_OK,
+	WATCHTIME_NOT_FOUND,
+	WATCHTIME_RESTRICTED,
+	WATCHTIME_RATE_LIMITED,
+	WATCHTIME_NO_DURATION,
+	WATCHTIME_ERROR,
+};
+*/
 import "C"
 import (
+	"context"
+	"errors"
 	"runtime"
+	"time"
 
 	"go.astrophena.name/exp/watchtime"
 )
@@ -28,14 +42,37 @@ func Version() *C.char {
 	return C.CString(runtime.Version())
 }
 
+// WatchTime returns the watch time of the video, or an error message. It
+// stores one of the WATCHTIME_* constants in status.
+//
 //export WatchTime
-func WatchTime(id *C.char) *C.char {
+func WatchTime(id *C.char, status *C.int) *C.char {
 	goID := C.GoString(id)
-	t, err := watchtime.Fetch(goID)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	t, err := watchtime.FetchContext(ctx, goID)
+	*status = errorStatus(err)
 	if err != nil {
 		return C.CString(err.Error())
 	}
 	return C.CString(t.String())
 }
 
+func errorStatus(err error) C.int {
+	var rlErr *watchtime.RateLimitError
+	switch {
+	case err == nil:
+		return C.WATCHTIME_OK
+	case errors.Is(err, watchtime.ErrNotFound):
+		return C.WATCHTIME_NOT_FOUND
+	case errors.Is(err, watchtime.ErrRestricted):
+		return C.WATCHTIME_RESTRICTED
+	case errors.As(err, &rlErr):
+		return C.WATCHTIME_RATE_LIMITED
+	case errors.Is(err, watchtime.ErrNoDuration):
+		return C.WATCHTIME_NO_DURATION
+	}
+	return C.WATCHTIME_ERROR
+}
+
 func main() {}
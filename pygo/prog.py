@@ -6,7 +6,22 @@ print(f"Add: {lib.Add(1, 2)}")
 lib.Version.restype = ctypes.c_char_p
 print(f"Version: {lib.Version()}")
 
-lib.WatchTime.argtypes = [ctypes.c_char_p]
+# Statuses of WatchTime, as in the enum in lib.h.
+WATCHTIME_STATUSES = [
+    "OK",
+    "NOT_FOUND",
+    "RESTRICTED",
+    "RATE_LIMITED",
+    "NO_DURATION",
+    "ERROR",
+]
+
+lib.WatchTime.argtypes = [ctypes.c_char_p, ctypes.POINTER(ctypes.c_int)]
 lib.WatchTime.restype = ctypes.c_char_p
 video_id = b"h3h035Eyz5A"  # Sia - Unstoppable (Lyrics)
-print(f"Watch time: {lib.WatchTime(video_id)}")
+status = ctypes.c_int()
+result = lib.WatchTime(video_id, ctypes.byref(status))
+if WATCHTIME_STATUSES[status.value] == "OK":
+    print(f"Watch time: {result}")
+else:
+    print(f"Can't get watch time ({WATCHTIME_STATUSES[status.value]}): {result}")
@@ -1,9 +1,16 @@
-// Package watchtime fetches the watch time of YouTube videos.
+// Package watchtime fetches the watch time and other details of YouTube
+// videos.
 package watchtime
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.astrophena.name/exp/iso8601"
@@ -11,59 +18,399 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
-// Fetch returns a watch time of YouTube video with the supplied ID.
+// Client fetches watch times and other details of YouTube videos.
+type Client struct {
+	// HTTPClient is the HTTP client to use. Nil means http.DefaultClient.
+	HTTPClient *http.Client
+	// BaseURL is the URL of YouTube, without the trailing slash. Empty means
+	// https://www.youtube.com.
+	BaseURL string
+	// UserAgent is sent in the User-Agent header, if not empty.
+	UserAgent string
+}
+
+// DefaultClient is the Client used by Fetch, FetchContext and Lookup.
+var DefaultClient = &Client{}
+
+var (
+	// ErrNotFound is returned when the video doesn't exist or was removed.
+	ErrNotFound = errors.New("video not found")
+	// ErrRestricted is returned when the video is private or
+	// age-restricted, so its page has no details.
+	ErrRestricted = errors.New("video is private or age-restricted")
+	// ErrNoDuration is returned when the video page has no duration.
+	ErrNoDuration = errors.New("no duration found")
+)
+
+// RateLimitError is returned when YouTube rejects a request because of too
+// many requests.
+type RateLimitError struct {
+	// RetryAfter is how long to wait before trying again, as asked by the
+	// server, or zero if it didn't say.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter == 0 {
+		return "rate limited"
+	}
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+}
+
+// Video holds the details of a YouTube video, as given on its page.
+// Details the page doesn't have, or has in a format Lookup doesn't
+// understand, are left empty.
+type Video struct {
+	// ID is the ID of the video.
+	ID string
+	// Title is the title of the video.
+	Title string
+	// Channel is the name of the channel that uploaded the video.
+	Channel string
+	// UploadDate is when the video was uploaded. Pages often give just the
+	// date, which is then parsed as midnight in UTC.
+	UploadDate time.Time
+	// Duration is the watch time of the video.
+	Duration time.Duration
+	// LiveStatus tells whether the video is a live stream or a premiere,
+	// and if so, whether it is on.
+	LiveStatus LiveStatus
+	// LiveStart and LiveEnd are when the live stream or premiere starts and
+	// ends. LiveEnd is zero until it ends.
+	LiveStart, LiveEnd time.Time
+
+	hasDuration bool
+}
+
+// LiveStatus tells whether a video is a live stream or a premiere, and if so,
+// whether it is on.
+type LiveStatus int
+
+const (
+	// NotLive is a regular video.
+	NotLive LiveStatus = iota
+	// Upcoming is a live stream or premiere that hasn't started yet.
+	Upcoming
+	// Live is a live stream or premiere that is on now.
+	Live
+	// WasLive is a live stream or premiere that has ended.
+	WasLive
+)
+
+var liveStatusNames = []string{"not live", "upcoming", "live", "was live"}
+
+func (s LiveStatus) String() string {
+	if s < NotLive || s > WasLive {
+		return "LiveStatus(" + strconv.Itoa(int(s)) + ")"
+	}
+	return liveStatusNames[s]
+}
+
+// Fetch returns a watch time of YouTube video with the supplied ID, using
+// DefaultClient.
 func Fetch(videoID string) (time.Duration, error) {
-	url := "https://www.youtube.com/watch?v=" + videoID
+	return DefaultClient.FetchContext(context.Background(), videoID)
+}
+
+// FetchContext is like Fetch, but with a context.
+func FetchContext(ctx context.Context, videoID string) (time.Duration, error) {
+	return DefaultClient.FetchContext(ctx, videoID)
+}
 
-	r, err := http.Get(url)
+// Lookup returns the details of YouTube video with the supplied ID, using
+// DefaultClient.
+func Lookup(ctx context.Context, videoID string) (*Video, error) {
+	return DefaultClient.Lookup(ctx, videoID)
+}
+
+// Fetch returns a watch time of YouTube video with the supplied ID.
+func (c *Client) Fetch(videoID string) (time.Duration, error) {
+	return c.FetchContext(context.Background(), videoID)
+}
+
+// FetchContext returns a watch time of YouTube video with the supplied ID.
+// The context controls the whole request.
+//
+// Errors that callers may want to handle are ErrNotFound, ErrRestricted,
+// ErrNoDuration and *RateLimitError; check for them with errors.Is and
+// errors.As.
+func (c *Client) FetchContext(ctx context.Context, videoID string) (time.Duration, error) {
+	v, err := c.Lookup(ctx, videoID)
 	if err != nil {
-		return 0, fmt.Errorf("unable to fetch %s: %w", url, err)
+		return 0, err
+	}
+	if !v.hasDuration {
+		return 0, ErrNoDuration
+	}
+	return v.Duration, nil
+}
+
+// Lookup returns the details of YouTube video with the supplied ID. The
+// context controls the whole request.
+//
+// It returns the same errors as FetchContext, except for ErrNoDuration: the
+// Duration of a video whose page has none, such as a live stream that is
+// on, is zero.
+func (c *Client) Lookup(ctx context.Context, videoID string) (*Video, error) {
+	base := c.BaseURL
+	if base == "" {
+		base = "https://www.youtube.com"
+	}
+	watchURL := base + "/watch?v=" + url.QueryEscape(videoID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, watchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	httpc := c.HTTPClient
+	if httpc == nil {
+		httpc = http.DefaultClient
+	}
+	r, err := httpc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch %s: %w", watchURL, err)
 	}
 	defer r.Body.Close()
 
-	if r.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("server returned status code %d", r.StatusCode)
+	switch r.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	case http.StatusTooManyRequests:
+		return nil, &RateLimitError{RetryAfter: retryAfter(r.Header.Get("Retry-After"))}
+	default:
+		return nil, fmt.Errorf("server returned status code %d", r.StatusCode)
 	}
 
 	doc, err := goquery.NewDocumentFromReader(r.Body)
 	if err != nil {
-		return 0, fmt.Errorf("unable to initialize document: %w", err)
+		return nil, fmt.Errorf("unable to initialize document: %w", err)
 	}
 
-	var durs string
+	switch playabilityStatus(doc) {
+	case "ERROR":
+		return nil, ErrNotFound
+	case "LOGIN_REQUIRED":
+		return nil, ErrRestricted
+	}
 
-	doc.Find("meta").Each(func(i int, s *goquery.Selection) {
-		itemprop, ok := s.Attr("itemprop")
-		if !ok || itemprop != "duration" {
-			return
+	return parseVideo(doc, videoID)
+}
+
+// videoObject holds the properties of a schema.org VideoObject that Lookup
+// reads, as they are written on the page.
+type videoObject struct {
+	name, author, uploadDate, duration string
+	// Properties of the BroadcastEvent the video is published in, if any.
+	isLive             bool
+	startDate, endDate string
+}
+
+// parseVideo returns the details of the video from its page. They are taken
+// from the VideoObject in JSON-LD, and from the microdata in meta tags for
+// the properties that it lacks.
+func parseVideo(doc *goquery.Document, videoID string) (*Video, error) {
+	obj := microdata(doc)
+	jsonLD(doc, &obj)
+
+	v := &Video{
+		ID:         videoID,
+		Title:      obj.name,
+		Channel:    obj.author,
+		UploadDate: parseDate(obj.uploadDate),
+	}
+
+	if obj.duration != "" {
+		dur, err := iso8601.ParseDuration(obj.duration)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse duration: %w", err)
+		}
+		// Any day is 24 hours long in UTC, which is what a video's days are.
+		v.Duration, err = dur.TimeDuration(time.Unix(0, 0).UTC())
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert duration %s: %w", dur, err)
 		}
+		v.hasDuration = true
+	}
 
-		durs, ok = s.Attr("content")
-		if !ok || durs == "" {
-			return
+	if obj.isLive {
+		v.LiveStart, v.LiveEnd = parseDate(obj.startDate), parseDate(obj.endDate)
+		switch {
+		case !v.LiveEnd.IsZero():
+			v.LiveStatus = WasLive
+		case v.LiveStart.After(time.Now()):
+			v.LiveStatus = Upcoming
+		default:
+			v.LiveStatus = Live
 		}
+	}
+	return v, nil
+}
 
-		if itemprop == "duration" && durs != "" {
-			return
+// microdata returns the properties of the VideoObject from the microdata on
+// the page, which YouTube puts in meta and link tags.
+func microdata(doc *goquery.Document) videoObject {
+	item := doc.Find(`[itemtype$="schema.org/VideoObject"]`).First()
+	if item.Length() == 0 {
+		item = doc.Selection
+	}
+	// Nested items have properties of their own, such as the name of the
+	// author, so only the direct children of the video have its name.
+	name := item.ChildrenFiltered(`[itemprop="name"]`).AttrOr("content", "")
+	if name == "" {
+		name = doc.Find(`meta[name="title"]`).AttrOr("content", "")
+	}
+	uploadDate := itemProp(item, "uploadDate")
+	if uploadDate == "" {
+		uploadDate = itemProp(item, "datePublished")
+	}
+	pub := item.Find(`[itemprop="publication"]`)
+	return videoObject{
+		name:       name,
+		author:     itemProp(item.Find(`[itemprop="author"]`), "name"),
+		uploadDate: uploadDate,
+		duration:   itemProp(item, "duration"),
+		isLive:     isTrue(itemProp(pub, "isLiveBroadcast")),
+		startDate:  itemProp(pub, "startDate"),
+		endDate:    itemProp(pub, "endDate"),
+	}
+}
+
+// itemProp returns the content of the first element in sel with the
+// microdata property.
+func itemProp(sel *goquery.Selection, prop string) string {
+	return sel.Find(`[itemprop="`+prop+`"]`).First().AttrOr("content", "")
+}
+
+// jsonLD sets the properties of obj that the first VideoObject in JSON-LD
+// scripts on the page has.
+func jsonLD(doc *goquery.Document, obj *videoObject) {
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		var ld struct {
+			Type        string          `json:"@type"`
+			Name        string          `json:"name"`
+			Author      json.RawMessage `json:"author"`
+			UploadDate  string          `json:"uploadDate"`
+			Duration    string          `json:"duration"`
+			Publication json.RawMessage `json:"publication"`
 		}
+		if err := json.Unmarshal([]byte(s.Text()), &ld); err != nil || ld.Type != "VideoObject" {
+			return true
+		}
+
+		set := func(dst *string, v string) {
+			if v != "" {
+				*dst = v
+			}
+		}
+		set(&obj.name, ld.Name)
+		set(&obj.uploadDate, ld.UploadDate)
+		set(&obj.duration, ld.Duration)
+		// The author is either a name, or a Person or Organization.
+		if author, ok := firstOf[string](ld.Author); ok {
+			set(&obj.author, author)
+		} else if author, ok := firstOf[struct {
+			Name string `json:"name"`
+		}](ld.Author); ok {
+			set(&obj.author, author.Name)
+		}
+		if pub, ok := firstOf[struct {
+			IsLiveBroadcast json.RawMessage `json:"isLiveBroadcast"`
+			StartDate       string          `json:"startDate"`
+			EndDate         string          `json:"endDate"`
+		}](ld.Publication); ok {
+			obj.isLive = isTrue(strings.Trim(string(pub.IsLiveBroadcast), `"`))
+			set(&obj.startDate, pub.StartDate)
+			set(&obj.endDate, pub.EndDate)
+		}
+		return false
 	})
+}
 
-	if durs == "" {
-		return 0, fmt.Errorf("no duration found")
+// firstOf decodes a JSON-LD value, which is either a single value or a list
+// of them. It returns the first value of a list.
+func firstOf[T any](raw json.RawMessage) (T, bool) {
+	var v T
+	if len(raw) == 0 {
+		return v, false
+	}
+	if err := json.Unmarshal(raw, &v); err == nil {
+		return v, true
 	}
+	var vs []T
+	if err := json.Unmarshal(raw, &vs); err == nil && len(vs) > 0 {
+		return vs[0], true
+	}
+	return v, false
+}
 
-	dur, err := iso8601.ParseDuration(durs)
+// isTrue reports whether s is a true boolean, which YouTube writes as
+// "True".
+func isTrue(s string) bool {
+	return strings.EqualFold(s, "true")
+}
+
+// parseDate parses a date or date and time from the page, returning the zero
+// time if it can't.
+func parseDate(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	t, _, err := iso8601.ParseTime(s)
 	if err != nil {
-		return 0, fmt.Errorf("unable to parse duration: %w", err)
+		return time.Time{}
 	}
+	return t
+}
 
-	return timeDuration(dur), nil
+// retryAfter parses the value of the Retry-After header, which is either a
+// number of seconds or a date.
+func retryAfter(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(s); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
 }
 
-func timeDuration(d iso8601.Duration) time.Duration {
-	var dur time.Duration
-	dur = dur + (time.Duration(d.TH) * time.Hour)
-	dur = dur + (time.Duration(d.TM) * time.Minute)
-	dur = dur + (time.Duration(d.TS) * time.Second)
-	return dur
+// playerResponseVar is the variable the video page assigns the player
+// details to.
+const playerResponseVar = "ytInitialPlayerResponse = "
+
+// playabilityStatus returns the playability status of the video from the
+// player details on its page, such as "OK", "ERROR" for missing videos or
+// "LOGIN_REQUIRED" for private and age-restricted ones. It returns an empty
+// string if there are no player details.
+func playabilityStatus(doc *goquery.Document) string {
+	var status string
+	doc.Find("script").EachWithBreak(func(i int, s *goquery.Selection) bool {
+		text := s.Text()
+		i = strings.Index(text, playerResponseVar)
+		if i < 0 {
+			return true
+		}
+		var resp struct {
+			PlayabilityStatus struct {
+				Status string `json:"status"`
+			} `json:"playabilityStatus"`
+		}
+		// Decode reads only the object, and not the rest of the script.
+		dec := json.NewDecoder(strings.NewReader(text[i+len(playerResponseVar):]))
+		if err := dec.Decode(&resp); err == nil {
+			status = resp.PlayabilityStatus.Status
+		}
+		return false
+	})
+	return status
 }
@@ -1,11 +1,54 @@
 package watchtime
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
 
+// newTestServer returns a server that serves watch pages from testdata.
+// The fixtures are trimmed down to the markup Lookup reads.
+func newTestServer(t *testing.T, wantUA string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/watch" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("v") {
+		case "ratelimited":
+			w.Header().Set("Retry-After", "120")
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		case "slow":
+			<-r.Context().Done()
+			return
+		}
+		if got := r.Header.Get("User-Agent"); wantUA != "" && got != wantUA {
+			t.Errorf("got User-Agent %q, want %q", got, wantUA)
+		}
+		b, err := os.ReadFile(filepath.Join("testdata", filepath.Base(r.URL.Query().Get("v"))+".html"))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(b)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
 func TestFetch(t *testing.T) {
+	const ua = "watchtime-test/1.0"
+	srv := newTestServer(t, ua)
+	c := &Client{HTTPClient: srv.Client(), BaseURL: srv.URL, UserAgent: ua}
+
 	var cases = []struct {
 		id   string
 		want time.Duration
@@ -18,14 +61,144 @@ func TestFetch(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.id, func(t *testing.T) {
-			got, err := Fetch(tc.id)
+			got, err := c.Fetch(tc.id)
 			if err != nil {
 				t.Fatalf("Got an error when fetching %q: %v", tc.id, err)
 			}
 
 			if tc.want != got {
-				t.Fatalf(`got %v, want %q`, got, tc.want)
+				t.Fatalf(`got %v, want %v`, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	srv := newTestServer(t, "")
+	c := &Client{HTTPClient: srv.Client(), BaseURL: srv.URL}
+
+	cases := []struct {
+		id   string
+		want error
+	}{
+		{"doesnotexist", ErrNotFound},
+		{"unavailable", ErrNotFound},
+		{"private", ErrRestricted},
+		{"agerestricted", ErrRestricted},
+		{"noduration", ErrNoDuration},
+		{"live", ErrNoDuration},
+	}
+	for _, tc := range cases {
+		if _, err := c.Fetch(tc.id); !errors.Is(err, tc.want) {
+			t.Fatalf("%s: got error %v, want %v", tc.id, err, tc.want)
+		}
+	}
+
+	_, err := c.Fetch("ratelimited")
+	var rlErr *RateLimitError
+	if !errors.As(err, &rlErr) {
+		t.Fatalf("got error %v, want *RateLimitError", err)
+	}
+	if rlErr.RetryAfter != 2*time.Minute {
+		t.Fatalf("got RetryAfter %v, want 2m0s", rlErr.RetryAfter)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	srv := newTestServer(t, "")
+	c := &Client{HTTPClient: srv.Client(), BaseURL: srv.URL}
+
+	date := func(s string) time.Time {
+		t.Helper()
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	cases := []struct {
+		id   string
+		want Video
+	}{
+		{"HLrqNhgdiC0", Video{
+			ID:         "HLrqNhgdiC0",
+			Title:      "Video HLrqNhgdiC0",
+			Channel:    "Example Channel",
+			UploadDate: date("2012-01-01T00:00:00Z"),
+			Duration:   (6 * time.Minute) + (20 * time.Second),
+		}},
+		{"live", Video{
+			ID:         "live",
+			Title:      "24/7 radio",
+			Channel:    "Radio",
+			UploadDate: date("2022-02-24T08:00:00-08:00"),
+			LiveStatus: Live,
+			LiveStart:  date("2022-02-24T08:00:00-08:00"),
+		}},
+		{"waslive", Video{
+			ID:         "waslive",
+			Title:      "Launch stream",
+			Channel:    "Space",
+			UploadDate: date("2021-12-25T04:00:00-08:00"),
+			Duration:   (1 * time.Hour) + (2 * time.Minute) + (3 * time.Second),
+			LiveStatus: WasLive,
+			LiveStart:  date("2021-12-25T04:20:00-08:00"),
+			LiveEnd:    date("2021-12-25T05:22:03-08:00"),
+		}},
+		// Has JSON-LD only.
+		{"premiere", Video{
+			ID:         "premiere",
+			Title:      "Trailer premiere",
+			Channel:    "Studio",
+			UploadDate: date("2099-06-01T00:00:00Z"),
+			Duration:   (2 * time.Minute) + (30 * time.Second),
+			LiveStatus: Upcoming,
+			LiveStart:  date("2099-06-01T18:00:00Z"),
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.id, func(t *testing.T) {
+			got, err := c.Lookup(context.Background(), tc.id)
+			if err != nil {
+				t.Fatalf("Got an error when looking up %q: %v", tc.id, err)
+			}
+			if got.ID != tc.want.ID || got.Title != tc.want.Title || got.Channel != tc.want.Channel ||
+				!got.UploadDate.Equal(tc.want.UploadDate) || got.Duration != tc.want.Duration ||
+				got.LiveStatus != tc.want.LiveStatus || !got.LiveStart.Equal(tc.want.LiveStart) ||
+				!got.LiveEnd.Equal(tc.want.LiveEnd) {
+				t.Fatalf("got %+v, want %+v", *got, tc.want)
 			}
 		})
 	}
+
+	if _, err := c.Lookup(context.Background(), "private"); !errors.Is(err, ErrRestricted) {
+		t.Fatalf("got error %v, want %v", err, ErrRestricted)
+	}
+}
+
+func TestFetchContext(t *testing.T) {
+	srv := newTestServer(t, "")
+	c := &Client{HTTPClient: srv.Client(), BaseURL: srv.URL}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.FetchContext(ctx, "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if got := retryAfter("30"); got != 30*time.Second {
+		t.Fatalf("got %v, want 30s", got)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := retryAfter(date); got < 59*time.Minute || got > time.Hour {
+		t.Fatalf("%s: got %v, want about an hour", date, got)
+	}
+	for _, s := range []string{"", "soon", "-5", "Mon, 02 Jan 2006 15:04:05 GMT"} {
+		if got := retryAfter(s); got != 0 {
+			t.Fatalf("%q: got %v, want 0", s, got)
+		}
+	}
 }